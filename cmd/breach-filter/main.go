@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hhertout/twirp_auth/lib/password"
+	"go.uber.org/zap"
+)
+
+// breach-filter builds the Bloom filter of breached passwords read by the password policy
+// through the "PASSWORD_BREACH_FILTER" environment variable.
+//
+// The dump is either the HIBP Pwned Passwords SHA-1 file of <hash>:<count> lines,
+// or a directory of range files as fetched by the HIBP downloader.
+//
+// Usage:
+//
+//	go run cmd/breach-filter/main.go -input pwned-passwords-sha1.txt -output breached.bloom -fp 0.001 -min-count 1
+func main() {
+	input := flag.String("input", "", "file or directory of the breach dump")
+	output := flag.String("output", "breached.bloom", "file of the Bloom filter to write")
+	falsePositiveRate := flag.Float64("fp", 0.001, "false positive rate of the filter")
+	minCount := flag.Int("min-count", 1, "ignore the hashes seen less than min-count times")
+	flag.Parse()
+
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	if *input == "" || *falsePositiveRate <= 0 || *falsePositiveRate >= 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// A first pass counts the hashes to size the filter
+	var count uint64
+	err := password.ReadDump(*input, *minCount, func(hash [sha1.Size]byte) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		logger.Fatal("Error during the read of the dump", zap.Error(err))
+	}
+
+	filter := password.NewBloomFilter(count, *falsePositiveRate)
+	err = password.ReadDump(*input, *minCount, func(hash [sha1.Size]byte) error {
+		filter.Add(hash)
+		return nil
+	})
+	if err != nil {
+		logger.Fatal("Error during the read of the dump", zap.Error(err))
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		logger.Fatal("Error during the creation of the filter", zap.Error(err))
+	}
+	defer file.Close()
+
+	size, err := filter.WriteTo(file)
+	if err != nil {
+		logger.Fatal("Error during the write of the filter", zap.Error(err))
+	}
+
+	logger.Info(fmt.Sprintf("🚀 Bloom filter of %d hashes written to %s", count, *output), zap.Int64("bytes", size))
+}
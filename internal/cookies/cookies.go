@@ -0,0 +1,144 @@
+package cookies
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+	"github.com/twitchtv/twirp"
+)
+
+// REFRESH_COOKIE_PATH restricts the refresh token cookie to the authentication service,
+// the only one reading it.
+const REFRESH_COOKIE_PATH = "/api/auth.AuthenticationService/"
+
+// Config describes the cookies handed over to the browsers in cookie mode.
+// The access and refresh tokens are kept in HttpOnly cookies, out of reach of the scripts,
+// and a CSRF token readable by the scripts is sent back in a header on every mutating request (double-submit).
+type Config struct {
+	Enabled     bool
+	AccessName  string
+	RefreshName string
+	CSRFName    string
+	CSRFHeader  string
+	Domain      string
+	Secure      bool
+	SameSite    http.SameSite
+}
+
+// NewConfigFromEnv reads the cookie configuration from the environment:
+// "COOKIE_MODE_ENABLED" (default false), "COOKIE_DOMAIN", "COOKIE_SECURE" (default true),
+// "COOKIE_SAME_SITE" (Strict, Lax or None, default Strict) and "COOKIE_PREFIX" prepended to the cookie names.
+func NewConfigFromEnv() (Config, error) {
+	enabled, err := config.Bool("COOKIE_MODE_ENABLED", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	secure, err := config.Bool("COOKIE_SECURE", true)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var sameSite http.SameSite
+	switch value := os.Getenv("COOKIE_SAME_SITE"); strings.ToLower(value) {
+	case "", "strict":
+		sameSite = http.SameSiteStrictMode
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		return Config{}, fmt.Errorf("env variable COOKIE_SAME_SITE has an unsupported value %s", value)
+	}
+
+	if sameSite == http.SameSiteNoneMode && !secure {
+		return Config{}, fmt.Errorf("env variable COOKIE_SAME_SITE=None requires COOKIE_SECURE")
+	}
+
+	prefix := os.Getenv("COOKIE_PREFIX")
+
+	return Config{
+		Enabled:     enabled,
+		AccessName:  prefix + "access_token",
+		RefreshName: prefix + "refresh_token",
+		CSRFName:    prefix + "csrf_token",
+		CSRFHeader:  "X-CSRF-Token",
+		Domain:      os.Getenv("COOKIE_DOMAIN"),
+		Secure:      secure,
+		SameSite:    sameSite,
+	}, nil
+}
+
+// SetTokens adds the cookies holding the tokens and a new CSRF token to the response of a RPC.
+func (c Config) SetTokens(ctx context.Context, accessToken string, accessExpiresAt time.Time, refreshToken string, refreshExpiresAt time.Time) error {
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	cookies := []*http.Cookie{
+		c.cookie(c.AccessName, accessToken, "/", accessExpiresAt, true),
+		c.cookie(c.RefreshName, refreshToken, REFRESH_COOKIE_PATH, refreshExpiresAt, true),
+		// The CSRF token is read by the scripts, to be sent back in the CSRF header
+		c.cookie(c.CSRFName, csrfToken, "/", refreshExpiresAt, false),
+	}
+
+	return c.write(ctx, cookies)
+}
+
+// Clear adds the cookies removing the tokens from the browser to the response of a RPC.
+func (c Config) Clear(ctx context.Context) error {
+	cookies := []*http.Cookie{
+		c.cookie(c.AccessName, "", "/", time.Time{}, true),
+		c.cookie(c.RefreshName, "", REFRESH_COOKIE_PATH, time.Time{}, true),
+		c.cookie(c.CSRFName, "", "/", time.Time{}, false),
+	}
+
+	return c.write(ctx, cookies)
+}
+
+// cookie builds a cookie of the configuration. A zero expiration date deletes the cookie.
+func (c Config) cookie(name string, value string, path string, expiresAt time.Time, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.Domain,
+		Expires:  expiresAt,
+		Secure:   c.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+	if expiresAt.IsZero() {
+		cookie.MaxAge = -1
+	}
+
+	return cookie
+}
+
+func (c Config) write(ctx context.Context, cookies []*http.Cookie) error {
+	for _, cookie := range cookies {
+		if err := twirp.AddHTTPResponseHeader(ctx, "Set-Cookie", cookie.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newCSRFToken generates a random CSRF token of 32 bytes.
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
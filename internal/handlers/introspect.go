@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"go.uber.org/zap"
+)
+
+// IntrospectionResponse is the response of the introspection endpoint, as described by RFC 7662.
+// Only "active" is set for an inactive token.
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientId  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	// Cnf holds the thumbprint of the DPoP key a bound token is tied to.
+	Cnf *crypto.Confirmation `json:"cnf,omitempty"`
+	// Act names the clients acting on behalf of the subject of an exchanged token.
+	Act *crypto.Actor `json:"act,omitempty"`
+}
+
+// Introspect serves the token introspection endpoint described by RFC 7662, for the services
+// that cannot verify the tokens by themselves. The token is posted as a form parameter,
+// along with an optional "token_type_hint". The tokens of any audience are introspected,
+// the response carries the audience for the client to check it.
+//
+// Parameters:
+// - logger: the logger used to report the introspections and their errors.
+// - clients: the clients allowed to call the endpoint, authenticated by client credentials or API key.
+// - tokenService: the service inspecting the tokens.
+//
+// @route /oauth/introspect
+func Introspect(logger *zap.Logger, clients *crypto.ClientRegistry, tokenService *services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		client, ok := clients.AuthenticateRequest(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		token := r.PostFormValue("token")
+		if token == "" {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		introspection, err := tokenService.Introspect(token, r.PostFormValue("token_type_hint"))
+		if err != nil {
+			logger.Error("Error during the introspection of a token", zap.Error(err))
+			writeOAuthError(w, http.StatusInternalServerError, "server_error")
+			return
+		}
+
+		logger.Debug("Token introspected", zap.String("client_id", client.Id), zap.Bool("active", introspection.Active))
+
+		writeJSON(w, http.StatusOK, newIntrospectionResponse(introspection))
+	}
+}
+
+// newIntrospectionResponse maps the state of a token to the introspection response.
+func newIntrospectionResponse(introspection services.Introspection) IntrospectionResponse {
+	if !introspection.Active {
+		return IntrospectionResponse{Active: false}
+	}
+
+	claims := introspection.Claims
+	response := IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientId:  claims.ClientId,
+		Username:  claims.Email,
+		TokenType: introspection.TokenType,
+		Sub:       claims.Subject,
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+		Roles:     claims.Roles,
+		Cnf:       claims.Cnf,
+		Act:       claims.Act,
+	}
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		response.Nbf = claims.NotBefore.Unix()
+	}
+
+	return response
+}
+
+// writeJSON writes a JSON response that must not be cached, since it describes credentials.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeOAuthError writes an OAuth 2.0 error response, as described by RFC 6749 section 5.2.
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
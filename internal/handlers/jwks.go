@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"go.uber.org/zap"
+)
+
+// JWKS serves the public keys verifying the issued tokens as a JSON Web Key Set.
+//
+// Parameters:
+// - logger: the logger used to report encoding errors.
+// - provider: the provider of the public keys, usually the JWT service.
+// - maxAge: returns how long clients may cache the key set, so that caches expire when the keys rotate.
+//
+// @route /.well-known/jwks.json
+func JWKS(logger *zap.Logger, provider crypto.PublicKeyProviderInterface, maxAge func() time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		set, err := crypto.NewJWKSet(provider.PublicKeys())
+		if err != nil {
+			logger.Error("Error during the encoding of the key set", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge().Seconds())))
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(set)
+	}
+}
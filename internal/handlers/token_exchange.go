@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"go.uber.org/zap"
+)
+
+// Grant and token types of the token exchange, as described by RFC 8693.
+const (
+	GRANT_TYPE_TOKEN_EXCHANGE = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TOKEN_TYPE_ACCESS_TOKEN   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// TokenExchangeResponse is the response of a successful token exchange, as described by RFC 8693 section 2.2.1.
+type TokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+// TokenExchange serves the token endpoint for the token exchange grant described by RFC 8693.
+// A backend service acting for a user posts the access token of the user as "subject_token"
+// and receives a token restricted to the "audience" and, optionally, to a narrower "scope" and "roles"
+// (space separated). The issued token carries an act claim naming the client.
+//
+// Parameters:
+// - logger: the logger used to report the exchanges and their errors.
+// - clients: the registered clients, authenticated by client credentials or API key.
+// - exchangeClients: the ids of the registered clients allowed to exchange tokens, no client when empty.
+// - tokenService: the service verifying the subject tokens and issuing the exchanged tokens.
+// - audiences: the audiences the tokens can be exchanged for, no audience when empty.
+// - self: the audience of the tokens of this service, tokens are never exchanged for it.
+//
+// @route /oauth/token
+func TokenExchange(logger *zap.Logger, clients *crypto.ClientRegistry, exchangeClients []string, tokenService *services.TokenService, audiences []string, self string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		client, ok := clients.AuthenticateRequest(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		// The clients only allowed to introspect the tokens cannot act on behalf of the users
+		if !slices.Contains(exchangeClients, client.Id) {
+			writeOAuthError(w, http.StatusBadRequest, "unauthorized_client")
+			return
+		}
+
+		if r.PostFormValue("grant_type") != GRANT_TYPE_TOKEN_EXCHANGE {
+			writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+
+		subjectToken := r.PostFormValue("subject_token")
+		if subjectToken == "" || r.PostFormValue("subject_token_type") != TOKEN_TYPE_ACCESS_TOKEN {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		if requested := r.PostFormValue("requested_token_type"); requested != "" && requested != TOKEN_TYPE_ACCESS_TOKEN {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		audience := r.PostFormValue("audience")
+		// An exchanged token accepted by this service would let the client call it on behalf of the user
+		if audience == "" || audience == self || !slices.Contains(audiences, audience) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_target")
+			return
+		}
+
+		exchanged, err := tokenService.Exchange(subjectToken, crypto.Delegation{
+			Actor:    client.Id,
+			Audience: audience,
+			Scope:    strings.Fields(r.PostFormValue("scope")),
+			Roles:    strings.Fields(r.PostFormValue("roles")),
+		})
+		if errors.Is(err, services.ErrInvalidAccessToken) || errors.Is(err, crypto.ErrBoundSubjectToken) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+		if errors.Is(err, crypto.ErrScopeNotGranted) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_scope")
+			return
+		}
+		if err != nil {
+			logger.Error("Error during the exchange of a token", zap.Error(err))
+			writeOAuthError(w, http.StatusInternalServerError, "server_error")
+			return
+		}
+
+		logger.Info("Token exchanged", zap.String("client_id", client.Id), zap.String("audience", audience))
+
+		writeJSON(w, http.StatusOK, TokenExchangeResponse{
+			AccessToken:     exchanged.AccessToken,
+			IssuedTokenType: TOKEN_TYPE_ACCESS_TOKEN,
+			TokenType:       "Bearer",
+			ExpiresIn:       int64(time.Until(exchanged.ExpiresAt).Seconds()),
+			Scope:           exchanged.Scope,
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Schedule runs a job in a dedicated goroutine every time the interval elapses.
+// Errors returned by the job are logged and do not stop the schedule.
+// Returns a function that stops the schedule.
+func Schedule(logger *zap.Logger, name string, interval time.Duration, job func() error) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := job(); err != nil {
+					logger.Error("Scheduled job failed", zap.String("job", name), zap.Error(err))
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
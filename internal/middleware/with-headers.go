@@ -2,31 +2,172 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
+	"path"
+	"strings"
 
+	"github.com/hhertout/twirp_auth/internal/cookies"
 	"github.com/hhertout/twirp_auth/internal/hooks"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/twitchtv/twirp"
 )
 
+// DPoP configures the validation of the DPoP proofs sent along with the requests.
+type DPoP struct {
+	Verifier *crypto.DPoPVerifier
+	// BaseURL replaces the scheme and the host of the request when rebuilding the URL compared to the "htu" claim,
+	// for a service running behind a proxy. The scheme and the host of the request are used when empty.
+	BaseURL string
+}
+
+// csrfExemptMethods are the RPCs that do not change any state, or that authenticate with credentials,
+// and thus do not require a CSRF token in cookie mode.
+var csrfExemptMethods = map[string]bool{
+	"Login":        true,
+	"Register":     true,
+	"CheckToken":   true,
+	"ListSessions": true,
+	// The reset token is the credential of the password reset
+	"RequestPasswordReset": true,
+	"ConfirmPasswordReset": true,
+}
+
+// Options configures the optional checks of WithHeaders.
+type Options struct {
+	// DPoP validates the DPoP proofs, nil to ignore them.
+	DPoP *DPoP
+	// Cookies reads the tokens from the cookies when the cookie mode is enabled, nil to ignore the cookies.
+	Cookies *cookies.Config
+}
+
 // WithHeaders is a middleware function that wraps an existing http.Handler.
 // It adds specific headers from the incoming HTTP request to the request context.
+// The access token is stored without its "Bearer" or "DPoP" authorization scheme.
+//
+// In cookie mode, the access token is read from its cookie when the request has no Authorization header,
+// and the refresh token cookie is stored in the context. The requests authenticated by cookies must then send
+// the CSRF token of the CSRF cookie in the CSRF header, except for the RPCs in csrfExemptMethods.
+//
+// When the DPoP option is set, the DPoP proof of the request is validated and the thumbprint of its key is stored
+// in the context, to be compared with the key the access token is bound to. A request authorized with
+// the "DPoP" scheme must carry a proof bound to its access token.
 //
 // Parameters:
 // - base: the original http.Handler to be wrapped.
+// - options: the cookie and DPoP configurations.
 //
 // Returns:
 // - An http.Handler that processes the request with the added headers in the context.
-func WithHeaders(base http.Handler) http.Handler {
+func WithHeaders(base http.Handler, options Options) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		scheme, token := splitAuthorization(r.Header.Get("Authorization"))
+
+		if options.Cookies != nil && options.Cookies.Enabled && token == "" {
+			accessToken := cookieValue(r, options.Cookies.AccessName)
+			refreshToken := cookieValue(r, options.Cookies.RefreshName)
+
+			if accessToken != "" || refreshToken != "" {
+				if !csrfExemptMethods[path.Base(r.URL.Path)] && !validCSRF(r, options.Cookies) {
+					twirp.WriteError(w, twirp.PermissionDenied.Error("invalid CSRF token"))
+					return
+				}
+			}
+
+			if accessToken != "" {
+				scheme, token = "Cookie", accessToken
+			}
+			ctx = context.WithValue(ctx, hooks.ServerContextKey("refresh-token-cookie"), refreshToken)
+		}
+
 		ctx = context.WithValue(ctx, hooks.ServerContextKey("route"), r.URL.Path)
 		ctx = context.WithValue(ctx, hooks.ServerContextKey("user-agent"), r.Header.Get("User-Agent"))
 		ctx = context.WithValue(ctx, hooks.ServerContextKey("x-forwarded-for"), r.Header.Get("X-Forwarded-For"))
 		ctx = context.WithValue(ctx, hooks.ServerContextKey("remote-addr"), r.Header.Get("Remote-Addr"))
-		ctx = context.WithValue(ctx, hooks.ServerContextKey("Authorization"), r.Header.Get("Authorization"))
+		ctx = context.WithValue(ctx, hooks.ServerContextKey("Authorization"), token)
+		ctx = context.WithValue(ctx, hooks.ServerContextKey("authorization-scheme"), scheme)
+
+		if options.DPoP != nil {
+			jkt, err := options.DPoP.verify(r, scheme, token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+				twirp.WriteError(w, twirp.Unauthenticated.Error(err.Error()))
+				return
+			}
+			ctx = context.WithValue(ctx, hooks.ServerContextKey("dpop-jkt"), jkt)
+		}
 
 		r = r.WithContext(ctx)
 
 		base.ServeHTTP(w, r)
 	})
 }
+
+// verify validates the DPoP proof of the request, if any.
+// Returns the thumbprint of the key of the proof, empty when the request has no proof.
+func (d *DPoP) verify(r *http.Request, scheme string, token string) (string, error) {
+	proofs := r.Header.Values("DPoP")
+	if len(proofs) > 1 {
+		return "", crypto.ErrInvalidDPoPProof
+	}
+	if len(proofs) == 0 {
+		if scheme == "DPoP" {
+			return "", crypto.ErrInvalidDPoPProof
+		}
+		return "", nil
+	}
+
+	// The proof is only bound to the access token when the token is sent with the DPoP scheme
+	accessToken := ""
+	if scheme == "DPoP" {
+		accessToken = token
+	}
+
+	return d.Verifier.Verify(proofs[0], r.Method, d.requestURL(r), accessToken)
+}
+
+// requestURL rebuilds the URL of the request, as seen by the client.
+func (d *DPoP) requestURL(r *http.Request) string {
+	if d.BaseURL != "" {
+		return strings.TrimSuffix(d.BaseURL, "/") + r.URL.Path
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// cookieValue returns the value of a cookie of the request, empty if it is missing.
+func cookieValue(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// validCSRF checks that the CSRF header of the request matches its CSRF cookie.
+func validCSRF(r *http.Request, config *cookies.Config) bool {
+	header := r.Header.Get(config.CSRFHeader)
+	cookie := cookieValue(r, config.CSRFName)
+
+	return header != "" && subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) == 1
+}
+
+// splitAuthorization splits the Authorization header into its scheme and its token.
+// A header without a known scheme is returned as the token itself.
+func splitAuthorization(header string) (string, string) {
+	for _, scheme := range []string{"Bearer", "DPoP"} {
+		if len(header) > len(scheme) && strings.EqualFold(header[:len(scheme)+1], scheme+" ") {
+			return scheme, strings.TrimSpace(header[len(scheme)+1:])
+		}
+	}
+
+	return "", header
+}
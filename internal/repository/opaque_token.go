@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/database"
+)
+
+// OpaqueTokenRepository persists the opaque access tokens.
+// It implements crypto.OpaqueTokenStoreInterface, the tokens it stores are already hashed.
+type OpaqueTokenRepository struct {
+	dbPool *sql.DB
+}
+
+// NewOpaqueTokenRepository creates a new instance of OpaqueTokenRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewOpaqueTokenRepository(customSource *sql.DB) (*OpaqueTokenRepository, error) {
+	if customSource != nil {
+		return &OpaqueTokenRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &OpaqueTokenRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+func (r OpaqueTokenRepository) Create(record crypto.OpaqueTokenRecord) error {
+	_, err := r.dbPool.Exec(`
+		INSERT INTO opaque_token (token_hash, jti, claims, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, record.TokenHash, record.Jti, string(record.Claims), record.ExpiresAt)
+
+	return err
+}
+
+func (r OpaqueTokenRepository) FindOneByHash(tokenHash string) (crypto.OpaqueTokenRecord, error) {
+	var record crypto.OpaqueTokenRecord
+	var claims string
+	err := r.dbPool.QueryRow(`
+		SELECT token_hash, jti, claims, expires_at
+		FROM opaque_token
+		WHERE token_hash=$1
+	`, tokenHash).Scan(&record.TokenHash, &record.Jti, &claims, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return crypto.OpaqueTokenRecord{}, nil
+	}
+	if err != nil {
+		return record, err
+	}
+
+	record.Claims = []byte(claims)
+	return record, nil
+}
+
+// DeleteExpired removes the tokens that are expired.
+func (r OpaqueTokenRepository) DeleteExpired() (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM opaque_token
+		WHERE expires_at < NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
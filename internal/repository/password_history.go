@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/hhertout/twirp_auth/pkg/database"
+)
+
+// PasswordHistoryRepository reads the previous password hashes of the users.
+// The hashes are recorded by the database whenever a password is replaced, rehashes excepted.
+type PasswordHistoryRepository struct {
+	dbPool *sql.DB
+}
+
+// NewPasswordHistoryRepository creates a new instance of PasswordHistoryRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewPasswordHistoryRepository(customSource *sql.DB) (*PasswordHistoryRepository, error) {
+	if customSource != nil {
+		return &PasswordHistoryRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &PasswordHistoryRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+// FindLatest returns the hashes of the previous passwords of the user, the most recent first.
+func (r PasswordHistoryRepository) FindLatest(userId string, limit int) ([]string, error) {
+	hashes := []string{}
+	rows, err := r.dbPool.Query(`
+		SELECT password
+		FROM password_history
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userId, limit)
+	if err != nil {
+		return hashes, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// Prune deletes the previous passwords of the user beyond the most recent ones to keep.
+func (r PasswordHistoryRepository) Prune(userId string, keep int) (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM password_history
+		WHERE user_id=$1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id=$1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`, userId, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
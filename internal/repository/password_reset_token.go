@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hhertout/twirp_auth/pkg/database"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// PasswordResetTokenRepository persists the password reset tokens, only their hashes are stored.
+type PasswordResetTokenRepository struct {
+	dbPool *sql.DB
+}
+
+// NewPasswordResetTokenRepository creates a new instance of PasswordResetTokenRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewPasswordResetTokenRepository(customSource *sql.DB) (*PasswordResetTokenRepository, error) {
+	if customSource != nil {
+		return &PasswordResetTokenRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &PasswordResetTokenRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+func (r PasswordResetTokenRepository) Create(userId string, tokenHash string, expiresAt time.Time) (int, error) {
+	res, err := r.dbPool.Exec(`
+		INSERT INTO password_reset_token (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userId, tokenHash, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r PasswordResetTokenRepository) FindOneByHash(tokenHash string) (dto.PasswordResetToken, error) {
+	var token dto.PasswordResetToken
+	err := r.dbPool.QueryRow(`
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM password_reset_token
+		WHERE token_hash=$1
+	`, tokenHash).Scan(&token.Id, &token.UserId, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt)
+	if err == sql.ErrNoRows {
+		return dto.PasswordResetToken{}, nil
+	}
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+// ResetPassword consumes a reset token and sets the new password of its user in a single transaction,
+// along with every other outstanding token of the user. The token must be neither used nor expired:
+// 0 affected rows means the token can no longer be used, the password is then left unchanged.
+func (r PasswordResetTokenRepository) ResetPassword(id string, userId string, password string) (int, error) {
+	tx, err := r.dbPool.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE password_reset_token
+		SET used_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND used_at IS NULL AND expires_at > NOW()
+	`, id, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE "user"
+		SET password=$1
+		WHERE id=$2
+	`, password, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE password_reset_token
+		SET used_at=NOW()
+		WHERE user_id=$1 AND used_at IS NULL
+	`, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+// DeleteInactive removes the tokens that are used or expired.
+func (r PasswordResetTokenRepository) DeleteInactive() (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM password_reset_token
+		WHERE used_at IS NOT NULL OR expires_at < NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hhertout/twirp_auth/pkg/database"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+type RefreshTokenRepository struct {
+	dbPool *sql.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of RefreshTokenRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewRefreshTokenRepository(customSource *sql.DB) (*RefreshTokenRepository, error) {
+	if customSource != nil {
+		return &RefreshTokenRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &RefreshTokenRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+// CreateFamily stores the first refresh token of a new family and returns the id of the family.
+// The session, the remember me flag and the DPoP key thumbprint are kept by every token of the family.
+func (r RefreshTokenRepository) CreateFamily(userId string, sessionId string, tokenHash string, expiresAt time.Time, rememberMe bool, dpopJkt *string) (string, error) {
+	var familyId string
+	err := r.dbPool.QueryRow(`
+		INSERT INTO refresh_token (user_id, session_id, token_hash, expires_at, remember_me, dpop_jkt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING family_id
+	`, userId, sessionId, tokenHash, expiresAt, rememberMe, dpopJkt).Scan(&familyId)
+	if err != nil {
+		return "", err
+	}
+
+	return familyId, nil
+}
+
+// CreateInFamily stores a refresh token issued by the rotation of a token of the given family.
+func (r RefreshTokenRepository) CreateInFamily(userId string, familyId string, sessionId *string, tokenHash string, expiresAt time.Time, rememberMe bool, dpopJkt *string) (int, error) {
+	res, err := r.dbPool.Exec(`
+		INSERT INTO refresh_token (user_id, family_id, session_id, token_hash, expires_at, remember_me, dpop_jkt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, userId, familyId, sessionId, tokenHash, expiresAt, rememberMe, dpopJkt)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r RefreshTokenRepository) FindOneByHash(tokenHash string) (dto.RefreshToken, error) {
+	var token dto.RefreshToken
+	rows, err := r.dbPool.Query(`
+		SELECT id, user_id, family_id, token_hash, created_at, expires_at, used_at, revoked_at, remember_me, dpop_jkt, session_id
+		FROM refresh_token
+		WHERE token_hash=$1
+		LIMIT 1
+	`, tokenHash)
+	if err != nil {
+		return token, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(
+			&token.Id,
+			&token.UserId,
+			&token.FamilyId,
+			&token.TokenHash,
+			&token.CreatedAt,
+			&token.ExpiresAt,
+			&token.UsedAt,
+			&token.RevokedAt,
+			&token.RememberMe,
+			&token.DPoPJkt,
+			&token.SessionId,
+		)
+		if err != nil {
+			return token, err
+		}
+	}
+
+	return token, nil
+}
+
+// MarkAsUsed flags a refresh token as consumed by a rotation.
+// It only affects a token that was not used yet, so 0 affected rows means the token was already consumed.
+func (r RefreshTokenRepository) MarkAsUsed(id string) (int, error) {
+	res, err := r.dbPool.Exec(`
+		UPDATE refresh_token
+		SET used_at=NOW()
+		WHERE id=$1 AND used_at IS NULL
+	`, id)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// RevokeFamily revokes every refresh token of a family.
+func (r RefreshTokenRepository) RevokeFamily(familyId string) (int, error) {
+	res, err := r.dbPool.Exec(`
+		UPDATE refresh_token
+		SET revoked_at=NOW()
+		WHERE family_id=$1 AND revoked_at IS NULL
+	`, familyId)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
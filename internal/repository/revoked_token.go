@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hhertout/twirp_auth/pkg/database"
+)
+
+// RevokedTokenRepository persists the denylist of revoked token ids (jti).
+// An entry only needs to live as long as the token it revokes, expired entries can be pruned.
+type RevokedTokenRepository struct {
+	dbPool *sql.DB
+}
+
+// NewRevokedTokenRepository creates a new instance of RevokedTokenRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewRevokedTokenRepository(customSource *sql.DB) (*RevokedTokenRepository, error) {
+	if customSource != nil {
+		return &RevokedTokenRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &RevokedTokenRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+func (r RevokedTokenRepository) Create(jti string, expiresAt time.Time) (int, error) {
+	res, err := r.dbPool.Exec(`
+		INSERT INTO revoked_token (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func (r RevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := r.dbPool.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM revoked_token WHERE jti=$1)
+	`, jti).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpired removes the entries of the tokens that are expired anyway.
+func (r RevokedTokenRepository) DeleteExpired() (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM revoked_token
+		WHERE expires_at < NOW()
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hhertout/twirp_auth/pkg/database"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+type SessionRepository struct {
+	dbPool *sql.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewSessionRepository(customSource *sql.DB) (*SessionRepository, error) {
+	if customSource != nil {
+		return &SessionRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SessionRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+// Create stores a new session and returns its id.
+func (r SessionRepository) Create(userId string, userAgent string, clientIp string, expiresAt time.Time) (string, error) {
+	var id string
+	err := r.dbPool.QueryRow(`
+		INSERT INTO session (user_id, user_agent, client_ip, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, userId, userAgent, clientIp, expiresAt).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// FindActiveByUserId returns the sessions of the user that are neither revoked nor expired,
+// the most recently used first.
+func (r SessionRepository) FindActiveByUserId(userId string) ([]dto.Session, error) {
+	sessions := []dto.Session{}
+	rows, err := r.dbPool.Query(`
+		SELECT id, user_id, user_agent, client_ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM session
+		WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, userId)
+	if err != nil {
+		return sessions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session dto.Session
+		err := rows.Scan(
+			&session.Id,
+			&session.UserId,
+			&session.UserAgent,
+			&session.ClientIp,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+		)
+		if err != nil {
+			return sessions, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// IsActive reports whether the session exists and is neither revoked nor expired.
+func (r SessionRepository) IsActive(id string) (bool, error) {
+	var active bool
+	err := r.dbPool.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM session WHERE id=$1 AND revoked_at IS NULL AND expires_at > NOW())
+	`, id).Scan(&active)
+	if err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
+
+// Touch records a use of the session and extends it until the given date.
+func (r SessionRepository) Touch(id string, userAgent string, clientIp string, expiresAt time.Time) (int, error) {
+	res, err := r.dbPool.Exec(`
+		UPDATE session
+		SET last_seen_at=NOW(), user_agent=$2, client_ip=$3, expires_at=GREATEST(expires_at, $4)
+		WHERE id=$1 AND revoked_at IS NULL
+	`, id, userAgent, clientIp, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// Revoke revokes a session of the user along with its refresh tokens.
+// Returns 0 affected rows when the user has no such active session.
+func (r SessionRepository) Revoke(userId string, id string) (int, error) {
+	return r.revoke(`user_id=$1 AND id=$2`, userId, id)
+}
+
+// RevokeOthers revokes every session of the user but the given one, along with their refresh tokens.
+func (r SessionRepository) RevokeOthers(userId string, exceptId string) (int, error) {
+	return r.revoke(`user_id=$1 AND id<>$2`, userId, exceptId)
+}
+
+// RevokeAll revokes every session of the user, along with their refresh tokens.
+func (r SessionRepository) RevokeAll(userId string) (int, error) {
+	return r.revoke(`user_id=$1`, userId)
+}
+
+// revoke revokes the active sessions matching the condition and their refresh tokens in a single transaction.
+// The condition must never come from user input.
+func (r SessionRepository) revoke(condition string, args ...any) (int, error) {
+	tx, err := r.dbPool.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE session
+		SET revoked_at=NOW()
+		WHERE `+condition+` AND revoked_at IS NULL
+		RETURNING id
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		_, err = tx.Exec(`
+			UPDATE refresh_token
+			SET revoked_at=NOW()
+			WHERE session_id=$1 AND revoked_at IS NULL
+		`, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// DeleteInactive removes the sessions that expired or were revoked before the given date.
+func (r SessionRepository) DeleteInactive(before time.Time) (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM session
+		WHERE expires_at < $1 OR revoked_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/database"
+)
+
+// SIGNING_KEY_ROTATION_LOCK is the key of the Postgres advisory lock held by the instance rotating the signing keys.
+const SIGNING_KEY_ROTATION_LOCK = 7216400501
+
+// SigningKeyRepository persists the keys of the token signing keyring.
+// It implements crypto.KeyStoreInterface, the key material it stores is already sealed by the keyring.
+type SigningKeyRepository struct {
+	dbPool *sql.DB
+}
+
+// NewSigningKeyRepository creates a new instance of SigningKeyRepository.
+// If a custom database source is provided, it uses that source.
+// Otherwise, it connects to the default database.
+func NewSigningKeyRepository(customSource *sql.DB) (*SigningKeyRepository, error) {
+	if customSource != nil {
+		return &SigningKeyRepository{
+			customSource,
+		}, nil
+	} else {
+		dbService, err := database.Connect()
+		if err != nil {
+			return nil, err
+		}
+
+		return &SigningKeyRepository{
+			dbService.DbPool,
+		}, nil
+	}
+}
+
+func (r SigningKeyRepository) FindAll() ([]crypto.KeyRecord, error) {
+	records := []crypto.KeyRecord{}
+	rows, err := r.dbPool.Query(`
+		SELECT kid, algorithm, material, created_at, activates_at, deactivated_at
+		FROM signing_key
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return records, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record crypto.KeyRecord
+		err := rows.Scan(
+			&record.Kid,
+			&record.Algorithm,
+			&record.Material,
+			&record.CreatedAt,
+			&record.ActivatesAt,
+			&record.DeactivatedAt,
+		)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Create stores a new key and deactivates the previous ones when it activates, in a single transaction.
+func (r SigningKeyRepository) Create(record crypto.KeyRecord) error {
+	tx, err := r.dbPool.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE signing_key
+		SET deactivated_at=$1
+		WHERE deactivated_at IS NULL
+	`, record.ActivatesAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO signing_key (kid, algorithm, material, created_at, activates_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, record.Kid, record.Algorithm, record.Material, record.CreatedAt, record.ActivatesAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r SigningKeyRepository) DeleteDeactivatedBefore(before time.Time) (int, error) {
+	res, err := r.dbPool.Exec(`
+		DELETE FROM signing_key
+		WHERE deactivated_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// WithRotationLock runs fn while holding the session advisory lock SIGNING_KEY_ROTATION_LOCK.
+// The lock is taken on a dedicated connection of the pool, released once fn returns.
+// Returns false without running fn when another instance holds the lock.
+func (r SigningKeyRepository) WithRotationLock(fn func() error) (bool, error) {
+	ctx := context.Background()
+
+	conn, err := r.dbPool.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, SIGNING_KEY_ROTATION_LOCK).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, SIGNING_KEY_ROTATION_LOCK)
+
+	return true, fn()
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hhertout/twirp_auth/pkg/database"
 	"github.com/hhertout/twirp_auth/pkg/dto"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type UserRepository struct {
@@ -48,20 +49,34 @@ func (r UserRepository) Create(email string, password string, role []string) (in
 }
 
 func (r UserRepository) FindOneByEmail(email string) (dto.User, error) {
+	return r.findOne("email", email)
+}
+
+func (r UserRepository) FindOneById(id string) (dto.User, error) {
+	return r.findOne("id", id)
+}
+
+func (r UserRepository) FindOneByUuid(uuid string) (dto.User, error) {
+	return r.findOne("uuid", uuid)
+}
+
+// findOne returns the user that is not deleted whose column matches the value.
+// The column must never come from user input.
+func (r UserRepository) findOne(column string, value string) (dto.User, error) {
 	var user dto.User
 	rows, err := r.dbPool.Query(`
-		SELECT id, uuid, email, password 
+		SELECT id, uuid, email, password, role, token_version, password_changed_at, must_change_password
 		FROM "user" 
-		WHERE email=$1 AND deleted_at is null 
+		WHERE `+column+`=$1 AND deleted_at is null 
 		LIMIT 1
-	`, email)
+	`, value)
 	if err != nil {
 		return user, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		err := rows.Scan(&user.Id, &user.Uuuid, &user.Email, &user.Password)
+		err := rows.Scan(&user.Id, &user.Uuuid, &user.Email, &user.Password, pgtype.NewMap().SQLScanner(&user.Role), &user.TokenVersion, &user.PasswordChangedAt, &user.MustChangePassword)
 		if err != nil {
 			return user, err
 		}
@@ -118,6 +133,95 @@ func (r UserRepository) UpdatePassword(id string, password string) (int, error)
 	return int(affected), nil
 }
 
+// RehashPassword replaces the hash of the password of the user by a new hash of the same password,
+// if the stored hash is still the given one. Unlike UpdatePassword, the token version is not bumped
+// since the password did not change: the sessions of the user are kept.
+func (r UserRepository) RehashPassword(id string, oldHash string, newHash string) (int, error) {
+	tx, err := r.dbPool.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Read by the f_bump_token_version trigger, for the current transaction only
+	_, err = tx.Exec(`SELECT set_config('app.password_rehash', 'on', true)`)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE "user"
+		SET password=$1
+		WHERE id=$2 AND password=$3
+	`, newHash, id, oldHash)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// SetMustChangePassword flags the user to change its password on its next login.
+// The flag is cleared by the database when the password changes.
+func (r UserRepository) SetMustChangePassword(id string, required bool) (int, error) {
+	res, err := r.dbPool.Exec(`
+		UPDATE "user"
+		SET must_change_password=$1
+		WHERE id=$2
+	`, required, id)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// IncrementTokenVersion bumps the token version of the user, invalidating every outstanding token.
+// The version is also bumped by the database when the password, the email, the roles or the ban state change.
+func (r UserRepository) IncrementTokenVersion(id string) (int, error) {
+	res, err := r.dbPool.Exec(`
+		UPDATE "user"
+		SET token_version=token_version+1
+		WHERE id=$1
+	`, id)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// ExistsByEmail checks if a user has the email, the banned and deleted users included.
+func (r UserRepository) ExistsByEmail(email string) (bool, error) {
+	var exists bool
+	err := r.dbPool.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM "user" WHERE email=$1)
+	`, email).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// UpdateEmail replaces the email of a user.
+// The change bumps the token version of the user, the tokens carrying the previous email are refused from then on.
 func (r UserRepository) UpdateEmail(oldEmail string, newEmail string) (int, error) {
 	res, err := r.dbPool.Exec(`
 		UPDATE "user" 
@@ -153,11 +257,13 @@ func (r UserRepository) SoftDelete(email string) (int, error) {
 	return int(affected), nil
 }
 
+// RemoveSoftDelete restores a banned user.
+// Returns 0 affected rows when no banned user has this email.
 func (r UserRepository) RemoveSoftDelete(email string) (int, error) {
 	res, err := r.dbPool.Exec(`
 		UPDATE "user"
 		SET deleted_at=NULL
-		WHERE email=$1;
+		WHERE email=$1 AND deleted_at IS NOT NULL;
 	`, email)
 
 	if err != nil {
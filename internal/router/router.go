@@ -3,38 +3,317 @@ package router
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"os"
+	"time"
 
+	"github.com/hhertout/twirp_auth/internal/cookies"
+	"github.com/hhertout/twirp_auth/internal/handlers"
 	"github.com/hhertout/twirp_auth/internal/hooks"
+	"github.com/hhertout/twirp_auth/internal/jobs"
 	"github.com/hhertout/twirp_auth/internal/middleware"
 	"github.com/hhertout/twirp_auth/internal/repository"
 	"github.com/hhertout/twirp_auth/internal/server"
+	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/config"
 	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/lib/notify"
+	"github.com/hhertout/twirp_auth/lib/password"
 	"github.com/hhertout/twirp_auth/pkg/auth"
+	"github.com/hhertout/twirp_auth/pkg/auth/role"
 	"github.com/hhertout/twirp_auth/protobuf/proto_auth"
 	"github.com/hhertout/twirp_auth/protobuf/proto_user"
 	"github.com/twitchtv/twirp"
 	"go.uber.org/zap"
 )
 
+// KEYRING_RELOAD_INTERVAL is the interval at which every instance reloads the signing keys and rotates them when due.
+const KEYRING_RELOAD_INTERVAL = time.Minute
+
 func GetRouter(logger *zap.Logger) *http.ServeMux {
 	r, err := repository.NewUserRepository(nil)
 	if err != nil {
 		logger.Fatal("Error during the creation of the repository", zap.Error(err))
 	}
 
+	rt, err := repository.NewRefreshTokenRepository(nil)
+	if err != nil {
+		logger.Fatal("Error during the creation of the refresh token repository", zap.Error(err))
+	}
+
+	rvt, err := repository.NewRevokedTokenRepository(nil)
+	if err != nil {
+		logger.Fatal("Error during the creation of the revoked token repository", zap.Error(err))
+	}
+
+	sr, err := repository.NewSessionRepository(nil)
+	if err != nil {
+		logger.Fatal("Error during the creation of the session repository", zap.Error(err))
+	}
+
+	phr, err := repository.NewPasswordHistoryRepository(nil)
+	if err != nil {
+		logger.Fatal("Error during the creation of the password history repository", zap.Error(err))
+	}
+
+	keyring, initial_key, err := crypto.NewKeyringFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the creation of the keyring", zap.Error(err))
+	}
+
+	// Without a secret to seal them, the keys are not persisted and the keyring only lives in memory.
+	if secret := os.Getenv("JWT_KEYRING_SECRET"); secret != "" {
+		skr, err := repository.NewSigningKeyRepository(nil)
+		if err != nil {
+			logger.Fatal("Error during the creation of the signing key repository", zap.Error(err))
+		}
+		keyring.UseStore(skr, secret)
+	} else if keyring.RotationInterval > 0 {
+		logger.Warn("JWT_KEYRING_SECRET is not set, rotated keys are not shared between instances nor kept across restarts")
+	}
+
+	jwks_max_age, err := config.Duration("JWKS_MAX_AGE", time.Hour)
+	if err != nil {
+		logger.Fatal("Error during the configuration of the JWKS endpoint", zap.Error(err))
+	}
+
+	// A rotated key is published before it signs: the other instances reload the keys every minute,
+	// and the verifiers may cache the published keys for JWKS_MAX_AGE.
+	keyring.PublishDelay = KEYRING_RELOAD_INTERVAL + jwks_max_age
+
+	if err := keyring.Init(initial_key); err != nil {
+		logger.Fatal("Error during the initialization of the keyring", zap.Error(err))
+	}
+
+	token_config, err := crypto.NewTokenConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the configuration of the tokens", zap.Error(err))
+	}
+
+	jwt_service := crypto.NewKeyringJWTService(keyring)
+	jwt_service.Config = token_config
+	jwt_service.Denylist = rvt
+
+	// The token strategy selects the format of the access tokens, JWTs by default.
+	// Opaque tokens are random references to claims stored in the database,
+	// PASETO tokens carry the same claims as the JWTs without algorithm negotiation.
+	token_strategy := os.Getenv("TOKEN_STRATEGY")
+	var access_token_service crypto.JWTServiceInterface
+
+	switch token_strategy {
+	case "", crypto.STRATEGY_JWT:
+		token_strategy = crypto.STRATEGY_JWT
+		access_token_service = jwt_service
+	case crypto.STRATEGY_OPAQUE:
+		ot, err := repository.NewOpaqueTokenRepository(nil)
+		if err != nil {
+			logger.Fatal("Error during the creation of the opaque token repository", zap.Error(err))
+		}
+
+		opaque_service := crypto.NewOpaqueTokenService(ot)
+		opaque_service.Config = token_config
+		opaque_service.Denylist = rvt
+		access_token_service = opaque_service
+
+		jobs.Schedule(logger, "prune-opaque-tokens", time.Hour, func() error {
+			pruned, err := opaque_service.PruneExpired()
+			if err != nil {
+				return err
+			}
+			logger.Sugar().Debugf("Pruned %d expired opaque tokens", pruned)
+			return nil
+		})
+	case crypto.STRATEGY_PASETO:
+		paseto_service, err := crypto.NewPasetoServiceFromEnv()
+		if err != nil {
+			logger.Fatal("Error during the creation of the PASETO service", zap.Error(err))
+		}
+
+		paseto_service.Config = token_config
+		paseto_service.Denylist = rvt
+		access_token_service = paseto_service
+	default:
+		logger.Fatal("Unsupported token strategy", zap.String("TOKEN_STRATEGY", token_strategy))
+	}
+
+	lifetimes, err := crypto.NewLifetimePolicyFromEnv(role.ToString())
+	if err != nil {
+		logger.Fatal("Error during the configuration of the token lifetimes", zap.Error(err))
+	}
+
+	// A key retired before the tokens it signed expire would invalidate them early.
+	// The keys can be rotated on demand by the admins, the check holds without scheduled rotation.
+	if keyring.Grace < lifetimes.Longest(crypto.TOKEN_ACCESS) {
+		logger.Fatal("JWT_KEY_GRACE_PERIOD must be longer than the lifetime of the access tokens")
+	}
+
+	token_service := services.NewTokenService(r, rt, rvt, sr, access_token_service, crypto.NewRefreshTokenService(), lifetimes)
+
+	// Past this age, the logins only issue tokens restricted to the change of the password
+	token_service.PasswordMaxAge, err = config.Duration("PASSWORD_MAX_AGE", 0)
+	if err != nil {
+		logger.Fatal("Error during the configuration of the password expiry", zap.Error(err))
+	}
+
+	jobs.Schedule(logger, "prune-revoked-tokens", time.Hour, func() error {
+		pruned, err := token_service.PruneRevokedTokens()
+		if err != nil {
+			return err
+		}
+		logger.Sugar().Debugf("Pruned %d expired entries from the token denylist", pruned)
+		return nil
+	})
+
+	// Sessions are removed a month after they expired or were revoked.
+	jobs.Schedule(logger, "prune-sessions", time.Hour, func() error {
+		pruned, err := token_service.PruneSessions(time.Now().Add(-30 * 24 * time.Hour))
+		if err != nil {
+			return err
+		}
+		logger.Sugar().Debugf("Pruned %d inactive sessions", pruned)
+		return nil
+	})
+
+	// A single instance rotates the keys, the rotation lock is shared through the store
+	jobs.Schedule(logger, "rotate-signing-keys", KEYRING_RELOAD_INTERVAL, func() error {
+		if err := keyring.Reload(); err != nil {
+			return err
+		}
+
+		rotated, err := keyring.RotateIfDue()
+		if err != nil {
+			return err
+		}
+		if rotated {
+			logger.Info("Signing key rotated")
+		}
+
+		_, err = keyring.Prune()
+		return err
+	})
+
+	auth_manager := auth.NewAuthManager(auth.NewAuthDataLayer(r, sr), access_token_service, logger)
+
+	// Browsers opt in to the cookie mode on login, the tokens are then kept in HttpOnly cookies.
+	cookie_config, err := cookies.NewConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the configuration of the cookies", zap.Error(err))
+	}
+
+	// Stored hashes keep their own parameters, they are upgraded to these parameters on the next login.
+	password_service, err := crypto.NewPasswordServiceFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the configuration of the password hashing", zap.Error(err))
+	}
+
+	password_policy, err := password.NewPolicyFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the configuration of the password policy", zap.Error(err))
+	}
+
 	auth_server := &server.AuthenticationServer{
 		Logger:          logger,
 		UserRepository:  r,
-		PasswordService: crypto.NewPasswordService(),
-		JwtService:      crypto.NewJWTService(),
+		PasswordService: password_service,
+		JwtService:      access_token_service,
+		TokenService:    token_service,
+		AuthManager:     auth_manager,
+		Keyring:         keyring,
+		Cookies:         cookie_config,
+		PasswordPolicy:  password_policy,
+	}
+
+	impersonation_lifetime, err := config.Duration("IMPERSONATION_TTL", 10*time.Minute)
+	if err != nil {
+		logger.Fatal("Error during the configuration of the impersonation", zap.Error(err))
+	}
+
+	password_history_size, err := config.Int("PASSWORD_HISTORY_SIZE", 5)
+	if err != nil {
+		logger.Fatal("Error during the configuration of the password history", zap.Error(err))
+	}
+
+	// The self-service password reset is enabled by the page of the client completing it
+	var password_reset *services.PasswordReset
+	if reset_url := os.Getenv("PASSWORD_RESET_URL"); reset_url != "" {
+		parsed_reset_url, err := url.Parse(reset_url)
+		if err != nil || !parsed_reset_url.IsAbs() {
+			logger.Fatal("PASSWORD_RESET_URL must be an absolute URL", zap.String("PASSWORD_RESET_URL", reset_url))
+		}
+
+		reset_lifetime, err := config.Duration("PASSWORD_RESET_TTL", time.Hour)
+		if err != nil {
+			logger.Fatal("Error during the configuration of the password reset", zap.Error(err))
+		}
+
+		// The requests are throttled so that the endpoint cannot be used to flood an inbox
+		reset_email_limit, err := config.Int("PASSWORD_RESET_EMAIL_LIMIT", 3)
+		if err != nil {
+			logger.Fatal("Error during the configuration of the password reset", zap.Error(err))
+		}
+
+		reset_ip_limit, err := config.Int("PASSWORD_RESET_IP_LIMIT", 10)
+		if err != nil {
+			logger.Fatal("Error during the configuration of the password reset", zap.Error(err))
+		}
+
+		reset_limit_window, err := config.Duration("PASSWORD_RESET_LIMIT_WINDOW", time.Hour)
+		if err != nil {
+			logger.Fatal("Error during the configuration of the password reset", zap.Error(err))
+		}
+
+		reset_max_deliveries, err := config.Int("PASSWORD_RESET_MAX_DELIVERIES", 8)
+		if err != nil || reset_max_deliveries < 1 {
+			logger.Fatal("PASSWORD_RESET_MAX_DELIVERIES must be a positive integer", zap.Error(err))
+		}
+
+		reset_limits := services.ResetLimits{
+			PerEmail:      reset_email_limit,
+			PerIp:         reset_ip_limit,
+			Window:        reset_limit_window,
+			MaxDeliveries: reset_max_deliveries,
+		}
+
+		sender, err := notify.NewSenderFromEnv()
+		if err != nil {
+			logger.Fatal("Error during the configuration of the notification sender", zap.Error(err))
+		}
+
+		prt, err := repository.NewPasswordResetTokenRepository(nil)
+		if err != nil {
+			logger.Fatal("Error during the creation of the password reset token repository", zap.Error(err))
+		}
+
+		password_reset = services.NewPasswordReset(prt, r, sr, crypto.NewRefreshTokenService(), sender, parsed_reset_url, reset_lifetime, reset_limits)
+
+		jobs.Schedule(logger, "prune-password-reset-tokens", time.Hour, func() error {
+			pruned, err := password_reset.PruneTokens()
+			if err != nil {
+				return err
+			}
+			logger.Sugar().Debugf("Pruned %d inactive password reset tokens", pruned)
+			return nil
+		})
+
+		jobs.Schedule(logger, "prune-password-reset-limits", time.Minute, func() error {
+			logger.Sugar().Debugf("Pruned %d ended password reset limit windows", password_reset.PruneLimits())
+			return nil
+		})
 	}
 
 	user_server := &server.UserServer{
 		Logger:          logger,
 		UserRepository:  r,
-		PasswordService: crypto.NewPasswordService(),
-		JwtService:      crypto.NewJWTService(),
-		AuthManager:     auth.NewAuthManager(r),
+		PasswordService: password_service,
+		JwtService:      access_token_service,
+		AuthManager:     auth_manager,
+		TokenService:    token_service,
+		Cookies:         cookie_config,
+
+		ImpersonationLifetime: impersonation_lifetime,
+		PasswordPolicy:        password_policy,
+		PasswordHistory:       services.NewPasswordHistory(phr, password_service, password_history_size),
+		PasswordReset:         password_reset,
 	}
 
 	auth_handler := proto_auth.NewAuthenticationServiceServer(
@@ -49,8 +328,35 @@ func GetRouter(logger *zap.Logger) *http.ServeMux {
 		twirp.WithServerHooks(hooks.NewLoggingServerHooks(logger)),
 	)
 
-	wrapped_auth := middleware.WithHeaders(auth_handler)
-	wrapped_user := middleware.WithHeaders(user_handler)
+	// Clients opt in to DPoP by sending proofs, the tokens of a login with a proof are bound to its key.
+	dpop_enabled, err := config.Bool("DPOP_ENABLED", true)
+	if err != nil {
+		logger.Fatal("Error during the configuration of DPoP", zap.Error(err))
+	}
+
+	var dpop *middleware.DPoP
+	if dpop_enabled {
+		dpop_max_age, err := config.Duration("DPOP_PROOF_MAX_AGE", time.Minute)
+		if err != nil {
+			logger.Fatal("Error during the configuration of DPoP", zap.Error(err))
+		}
+
+		// The ids of the proofs are kept by each instance, a proof replayed against another instance is not detected
+		replay_cache := crypto.NewMemoryReplayCache()
+		dpop = &middleware.DPoP{
+			Verifier: crypto.NewDPoPVerifier(dpop_max_age, token_config.Leeway, replay_cache),
+			BaseURL:  os.Getenv("DPOP_BASE_URL"),
+		}
+
+		jobs.Schedule(logger, "prune-dpop-replay-cache", time.Minute, func() error {
+			logger.Sugar().Debugf("Pruned %d expired DPoP proof ids", replay_cache.Prune())
+			return nil
+		})
+	}
+
+	options := middleware.Options{DPoP: dpop, Cookies: &cookie_config}
+	wrapped_auth := middleware.WithHeaders(auth_handler, options)
+	wrapped_user := middleware.WithHeaders(user_handler, options)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +371,31 @@ func GetRouter(logger *zap.Logger) *http.ServeMux {
 		})
 	})
 
+	// Keys are only published when tokens are JWTs signed with asymmetric keys,
+	// a shared HS256 secret must never leave the service.
+	if token_strategy == crypto.STRATEGY_JWT && keyring.Algorithm != crypto.ALG_HS256 {
+		mux.HandleFunc("/.well-known/jwks.json", handlers.JWKS(logger, jwt_service, func() time.Duration {
+			return keyring.CacheMaxAge(jwks_max_age)
+		}))
+	}
+
+	clients, err := crypto.NewClientRegistryFromEnv()
+	if err != nil {
+		logger.Fatal("Error during the configuration of the OAuth clients", zap.Error(err))
+	}
+
+	// The introspection and token exchange endpoints are only exposed when some clients are allowed to call them.
+	// The token exchange also requires the clients allowed to exchange and the audiences they can exchange for.
+	if !clients.Empty() {
+		mux.HandleFunc("/oauth/introspect", handlers.Introspect(logger, clients, token_service))
+
+		exchange_clients := config.List("OAUTH_EXCHANGE_CLIENTS")
+		exchange_audiences := config.List("OAUTH_EXCHANGE_AUDIENCES")
+		if len(exchange_clients) > 0 && len(exchange_audiences) > 0 {
+			mux.HandleFunc("/oauth/token", handlers.TokenExchange(logger, clients, exchange_clients, token_service, exchange_audiences, token_config.Audience))
+		}
+	}
+
 	mux.Handle(auth_handler.PathPrefix(), wrapped_auth)
 	mux.Handle(user_handler.PathPrefix(), wrapped_user)
 
@@ -2,8 +2,12 @@ package server
 
 import (
 	"context"
+	"errors"
 
+	"github.com/hhertout/twirp_auth/internal/hooks"
 	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/auth/role"
 	"github.com/hhertout/twirp_auth/protobuf/proto_auth"
 	"github.com/twitchtv/twirp"
 )
@@ -16,6 +20,11 @@ func (s *AuthenticationServer) Login(ctx context.Context, creds *proto_auth.Logi
 		return nil, twirp.InvalidArgument.Error(err.Error())
 	}
 
+	// No password over the maximum length can match, it is refused before its costly verification
+	if s.PasswordPolicy.TooLong(creds.Password) {
+		return nil, twirp.Unauthenticated.Error("Invalid credentials")
+	}
+
 	user, err := s.UserRepository.FindOneByEmail(creds.Username)
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
@@ -34,12 +43,42 @@ func (s *AuthenticationServer) Login(ctx context.Context, creds *proto_auth.Logi
 		return nil, twirp.Unauthenticated.Error("Invalid credentials")
 	}
 
-	token, err := s.JwtService.Generate(creds.Username)
+	// Outdated hashes are upgraded while the password is at hand, a failure does not prevent the login
+	if s.PasswordService.NeedsRehash(user.Password) {
+		s.rehashPassword(user.Id, user.Password, creds.Password)
+	}
+
+	// A DPoP proof sent along with the credentials binds the tokens to the key of the client
+	dpopJkt, _ := ctx.Value(hooks.ServerContextKey("dpop-jkt")).(string)
+
+	pair, err := s.TokenService.Issue(user, services.IssueOptions{
+		RememberMe: creds.RememberMe,
+		DPoPJkt:    dpopJkt,
+		Client:     clientInfo(ctx),
+	})
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	return &proto_auth.LoginResponse{Token: token}, nil
+	// In cookie mode, the tokens are kept in HttpOnly cookies out of reach of the scripts of the browser
+	if s.Cookies.Enabled && creds.CookieMode {
+		if err := s.Cookies.SetTokens(ctx, pair.AccessToken, pair.ExpiresAt, pair.RefreshToken, pair.RefreshExpiresAt); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		return &proto_auth.LoginResponse{
+			Username:               user.Email,
+			ExpiresAt:              pair.ExpiresAt.Unix(),
+			PasswordChangeRequired: pair.PasswordChangeRequired,
+		}, nil
+	}
+
+	return &proto_auth.LoginResponse{
+		Token:                  pair.AccessToken,
+		RefreshToken:           pair.RefreshToken,
+		ExpiresAt:              pair.ExpiresAt.Unix(),
+		PasswordChangeRequired: pair.PasswordChangeRequired,
+	}, nil
 }
 
 // CheckToken checks if the token is valid
@@ -51,14 +90,176 @@ func (s *AuthenticationServer) CheckToken(ctx context.Context, req *proto_auth.C
 		return nil, twirp.InvalidArgument.Error("Token is empty")
 	}
 
-	valid, claims, err := s.JwtService.Verify(token)
-	if err != nil {
+	claims, err := s.TokenService.VerifyAccessToken(token)
+	if errors.Is(err, services.ErrInvalidAccessToken) {
 		return nil, twirp.Unauthenticated.Error(err.Error())
 	}
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &proto_auth.CheckTokenResponse{
+		Username:  claims.Email,
+		Uuid:      claims.Subject,
+		Roles:     claims.Roles,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		Jkt:       claims.BoundKey(),
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+// A refresh token can only be used once, presenting it again revokes its whole family.
+// The refresh tokens bound to a DPoP key require a proof of that key.
+// In cookie mode, the refresh token is read from its cookie and the new tokens are set in the cookies.
+//
+// @route /api/auth.AuthenticationService/Refresh
+func (s *AuthenticationServer) Refresh(ctx context.Context, req *proto_auth.RefreshRequest) (*proto_auth.RefreshResponse, error) {
+	refreshToken := req.GetRefreshToken()
+	fromCookie := false
+	if refreshToken == "" && s.Cookies.Enabled {
+		refreshToken = refreshTokenCookie(ctx)
+		fromCookie = true
+	}
+
+	if refreshToken == "" {
+		return nil, twirp.InvalidArgument.Error("Refresh token is empty")
+	}
 
-	if !valid {
+	dpopJkt, _ := ctx.Value(hooks.ServerContextKey("dpop-jkt")).(string)
+
+	pair, err := s.TokenService.Rotate(refreshToken, services.RotateOptions{DPoPJkt: dpopJkt, Client: clientInfo(ctx)})
+	if errors.Is(err, services.ErrRefreshTokenReused) {
+		s.Logger.Sugar().Warn("Refresh token reuse detected, the token family has been revoked")
+		return nil, twirp.Unauthenticated.Error("Invalid refresh token")
+	}
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
+		return nil, twirp.Unauthenticated.Error("Invalid refresh token")
+	}
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if fromCookie {
+		if err := s.Cookies.SetTokens(ctx, pair.AccessToken, pair.ExpiresAt, pair.RefreshToken, pair.RefreshExpiresAt); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		return &proto_auth.RefreshResponse{
+			ExpiresAt:              pair.ExpiresAt.Unix(),
+			PasswordChangeRequired: pair.PasswordChangeRequired,
+		}, nil
+	}
+
+	return &proto_auth.RefreshResponse{
+		Token:                  pair.AccessToken,
+		RefreshToken:           pair.RefreshToken,
+		ExpiresAt:              pair.ExpiresAt.Unix(),
+		PasswordChangeRequired: pair.PasswordChangeRequired,
+	}, nil
+}
+
+// Logout ends the session of the access token of the request: the access token and the session are revoked,
+// as well as the family of the refresh token when provided, which must have been issued to the same user.
+// An expired access token is accepted, so that an idle client can still log out. In cookie mode, the cookies are cleared.
+//
+// @route /api/auth.AuthenticationService/Logout
+func (s *AuthenticationServer) Logout(ctx context.Context, req *proto_auth.LogoutRequest) (*proto_auth.LogoutResponse, error) {
+	token, _ := ctx.Value(hooks.ServerContextKey("Authorization")).(string)
+	if token == "" {
+		return nil, twirp.Unauthenticated.Error("Token is missing")
+	}
+
+	refreshToken := req.GetRefreshToken()
+	if refreshToken == "" && s.Cookies.Enabled {
+		refreshToken = refreshTokenCookie(ctx)
+	}
+
+	err := s.TokenService.Logout(token, refreshToken)
+	if errors.Is(err, services.ErrInvalidAccessToken) {
 		return nil, twirp.Unauthenticated.Error("Invalid token")
 	}
+	if errors.Is(err, services.ErrTokenNotOwned) {
+		return nil, twirp.PermissionDenied.Error("Refresh token was issued to another user")
+	}
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the logout", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if s.Cookies.Enabled {
+		if err := s.Cookies.Clear(ctx); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+	}
+
+	return &proto_auth.LogoutResponse{Success: true}, nil
+}
+
+// RevokeToken revokes an access token or a refresh token, following RFC 7009.
+// The caller authenticates with its own access token and can only revoke the tokens issued to it.
+// Revoking an unknown or already revoked token succeeds.
+//
+// @route /api/auth.AuthenticationService/RevokeToken
+func (s *AuthenticationServer) RevokeToken(ctx context.Context, req *proto_auth.RevokeTokenRequest) (*proto_auth.RevokeTokenResponse, error) {
+	user, err := s.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{})
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return nil, twirp.Unauthenticated.Error(err.Error())
+	}
+
+	if req.GetToken() == "" {
+		return nil, twirp.InvalidArgument.Error("Token is empty")
+	}
+
+	err = s.TokenService.Revoke(user, req.GetToken())
+	if errors.Is(err, services.ErrTokenNotOwned) {
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the revocation of the token", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &proto_auth.RevokeTokenResponse{Success: true}, nil
+}
+
+// RotateSigningKey generates a new signing key, restricted to admins.
+// The key is published right away and signs the tokens once the publish delay of the keyring is over.
+// Tokens signed with the previous key stay valid until the end of the grace period.
+// The rotation is refused while another instance rotates the keys.
+//
+// @route /api/auth.AuthenticationService/RotateSigningKey
+func (s *AuthenticationServer) RotateSigningKey(ctx context.Context, req *proto_auth.RotateSigningKeyRequest) (*proto_auth.RotateSigningKeyResponse, error) {
+	_, err := s.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
 
-	return &proto_auth.CheckTokenResponse{Username: claims.Issuer}, nil
+	// The rotation happens under the lock of the scheduled rotation, the keys cannot be rotated twice at once
+	key, err := s.Keyring.Rotate()
+	if errors.Is(err, crypto.ErrRotationInProgress) {
+		return nil, twirp.Aborted.Error("Signing keys are being rotated")
+	}
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the rotation of the signing key", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	s.Logger.Sugar().Info("Signing key rotated, new kid ", key.Kid)
+
+	return &proto_auth.RotateSigningKeyResponse{Kid: key.Kid}, nil
+}
+
+// rehashPassword replaces an outdated hash of the password of a user by a hash made with the current format.
+func (s *AuthenticationServer) rehashPassword(userId string, oldHash string, password string) {
+	hash, err := s.PasswordService.Hash(password)
+	if err != nil {
+		s.Logger.Sugar().Error("Error during the rehash of the password", err)
+		return
+	}
+
+	if _, err := s.UserRepository.RehashPassword(userId, oldHash, hash); err != nil {
+		s.Logger.Sugar().Error("Error during the rehash of the password", err)
+	}
 }
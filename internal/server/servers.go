@@ -1,9 +1,19 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hhertout/twirp_auth/internal/cookies"
+	"github.com/hhertout/twirp_auth/internal/hooks"
 	"github.com/hhertout/twirp_auth/internal/repository"
+	"github.com/hhertout/twirp_auth/internal/services"
 	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/lib/password"
 	"github.com/hhertout/twirp_auth/pkg/auth"
+	"github.com/twitchtv/twirp"
 	"go.uber.org/zap"
 )
 
@@ -13,6 +23,12 @@ type AuthenticationServer struct {
 	UserRepository  *repository.UserRepository
 	JwtService      crypto.JWTServiceInterface
 	PasswordService crypto.PasswordServiceInterface
+	TokenService    *services.TokenService
+	AuthManager     auth.AuthManagerInterface
+	Keyring         *crypto.Keyring
+	Cookies         cookies.Config
+	// PasswordPolicy bounds the length of the passwords verified on login.
+	PasswordPolicy password.Policy
 }
 
 // UserServer implements the different servers
@@ -22,4 +38,61 @@ type UserServer struct {
 	JwtService      crypto.JWTServiceInterface
 	PasswordService crypto.PasswordServiceInterface
 	AuthManager     auth.AuthManagerInterface
+	TokenService    *services.TokenService
+	Cookies         cookies.Config
+	// ImpersonationLifetime is the lifetime of the tokens issued to the admins impersonating a user.
+	ImpersonationLifetime time.Duration
+	// PasswordPolicy is enforced on the passwords chosen on registration and on password change.
+	PasswordPolicy password.Policy
+	// PasswordHistory prevents the reuse of the last passwords on password change.
+	PasswordHistory *services.PasswordHistory
+	// PasswordReset sends the password reset links, nil when the self-service reset is disabled.
+	PasswordReset *services.PasswordReset
+}
+
+// clientInfo reads the device of the request from the values stored in the context by the WithHeaders middleware.
+// The client IP is the first address of X-Forwarded-For, or the remote address.
+func clientInfo(ctx context.Context) services.ClientInfo {
+	userAgent, _ := ctx.Value(hooks.ServerContextKey("user-agent")).(string)
+
+	clientIp, _ := ctx.Value(hooks.ServerContextKey("x-forwarded-for")).(string)
+	clientIp, _, _ = strings.Cut(clientIp, ",")
+	clientIp = strings.TrimSpace(clientIp)
+	if clientIp == "" {
+		clientIp, _ = ctx.Value(hooks.ServerContextKey("remote-addr")).(string)
+	}
+
+	return services.ClientInfo{UserAgent: userAgent, ClientIp: clientIp}
+}
+
+// refreshTokenCookie reads the refresh token cookie stored in the context by the WithHeaders middleware in cookie mode.
+func refreshTokenCookie(ctx context.Context) string {
+	token, _ := ctx.Value(hooks.ServerContextKey("refresh-token-cookie")).(string)
+	return token
+}
+
+// passwordPolicyError reports the violations of the password policy as an invalid argument error.
+// The "violations" metadata lists every failed rule, comma separated, and "violation_<rule>" describes each of them.
+func passwordPolicyError(violations []password.Violation) twirp.Error {
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+
+	err := twirp.InvalidArgument.Error("Password does not satisfy the password policy").
+		WithMeta("argument", "password").
+		WithMeta("violations", strings.Join(rules, ","))
+	for _, v := range violations {
+		err = err.WithMeta("violation_"+v.Rule, v.Message)
+	}
+
+	return err
+}
+
+// passwordReusedError reports a password found in the password history like a violation of the password policy.
+func passwordReusedError(size int) twirp.Error {
+	return passwordPolicyError([]password.Violation{{
+		Rule:    password.RULE_HISTORY,
+		Message: fmt.Sprintf("password must differ from the last %d passwords", size),
+	}})
 }
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/auth"
+	"github.com/hhertout/twirp_auth/pkg/auth/role"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+	"github.com/hhertout/twirp_auth/protobuf/proto_user"
+	"github.com/twitchtv/twirp"
+)
+
+// ListSessions lists the active sessions of the authenticated user.
+// Admins can list the sessions of another user by giving its username.
+// The sessions are not exposed to the tokens acting on behalf of the user.
+//
+// @route /api/user.UserService/ListSessions
+func (u *UserServer) ListSessions(ctx context.Context, req *proto_user.ListSessionsRequest) (*proto_user.ListSessionsResponse, error) {
+	user, claims, err := u.authenticateDirect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.sessionOwner(user, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := u.TokenService.ListSessions(target)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the listing of the sessions", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	response := &proto_user.ListSessionsResponse{Sessions: []*proto_user.Session{}}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, &proto_user.Session{
+			Id:         session.Id,
+			UserAgent:  session.UserAgent,
+			ClientIp:   session.ClientIp,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastSeenAt: session.LastSeenAt.Unix(),
+			ExpiresAt:  session.ExpiresAt.Unix(),
+			Current:    session.Id == claims.SessionId,
+		})
+	}
+
+	return response, nil
+}
+
+// RevokeSession revokes a session of the authenticated user, its tokens are refused from then on.
+// Admins can revoke a session of another user by giving its username.
+// The tokens acting on behalf of the user are refused.
+//
+// @route /api/user.UserService/RevokeSession
+func (u *UserServer) RevokeSession(ctx context.Context, req *proto_user.RevokeSessionRequest) (*proto_user.RevokeSessionResponse, error) {
+	user, _, err := u.authenticateDirect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SessionId == "" {
+		return nil, twirp.InvalidArgument.Error("Session id is empty")
+	}
+
+	// The session ids are UUIDs, any other value is refused before reaching the database
+	if _, err := uuid.Parse(req.SessionId); err != nil {
+		return nil, twirp.InvalidArgument.Error("Session id is not a valid UUID").WithMeta("argument", "session_id")
+	}
+
+	target, err := u.sessionOwner(user, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.TokenService.RevokeSession(target, req.SessionId)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		return nil, twirp.NotFound.Error("Session not found")
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the revocation of the session", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &proto_user.RevokeSessionResponse{Success: true}, nil
+}
+
+// RevokeOtherSessions revokes every session of the authenticated user but the current one.
+// The tokens acting on behalf of the user are refused.
+//
+// @route /api/user.UserService/RevokeOtherSessions
+func (u *UserServer) RevokeOtherSessions(ctx context.Context, req *proto_user.RevokeOtherSessionsRequest) (*proto_user.RevokeOtherSessionsResponse, error) {
+	user, claims, err := u.authenticateDirect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.SessionId == "" {
+		return nil, twirp.FailedPrecondition.Error("Token does not belong to a session, log in again")
+	}
+
+	revoked, err := u.TokenService.RevokeOtherSessions(user, claims.SessionId)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the revocation of the sessions", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &proto_user.RevokeOtherSessionsResponse{Revoked: int32(revoked)}, nil
+}
+
+// authenticateDirect authenticates the user of a token that is not acting on its behalf,
+// the sessions are managed by the user itself or by an admin.
+func (u *UserServer) authenticateDirect(ctx context.Context) (dto.User, crypto.Claims, error) {
+	user, claims, err := u.AuthManager.Authenticate(ctx)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return dto.User{}, crypto.Claims{}, twirp.Unauthenticated.Error(err.Error())
+	}
+
+	if claims.Act != nil {
+		return dto.User{}, crypto.Claims{}, twirp.PermissionDenied.Error(auth.ErrActingToken.Error())
+	}
+
+	return user, claims, nil
+}
+
+// sessionOwner returns the user whose sessions are managed: the authenticated user when the username is empty
+// or its own, another user when the authenticated user is an admin.
+func (u *UserServer) sessionOwner(user dto.User, username string) (dto.User, error) {
+	if username == "" || username == user.Email {
+		return user, nil
+	}
+
+	if !role.Contains(role.FromString(user.Role), string(role.ROLE_ADMIN)) {
+		return dto.User{}, twirp.PermissionDenied.Error("user does not have the required role")
+	}
+
+	target, err := u.UserRepository.FindOneByEmail(username)
+	if err != nil {
+		return dto.User{}, twirp.InternalErrorWith(err)
+	}
+	if target.Email != username {
+		return dto.User{}, twirp.NotFound.Error("User not found")
+	}
+
+	return target, nil
+}
@@ -2,12 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
 	"strings"
 
+	"github.com/hhertout/twirp_auth/internal/hooks"
 	"github.com/hhertout/twirp_auth/internal/services"
 	"github.com/hhertout/twirp_auth/pkg/auth/role"
 	"github.com/hhertout/twirp_auth/protobuf/proto_user"
 	"github.com/twitchtv/twirp"
+	"go.uber.org/zap"
 )
 
 // UserServer implements the different servers
@@ -26,6 +29,15 @@ func (u *UserServer) Register(ctx context.Context, req *proto_user.RegisterReque
 		return nil, twirp.InvalidArgument.Error(err.Error())
 	}
 
+	violations, err := u.PasswordPolicy.Check(req.Password, req.Username)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the password policy", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if len(violations) > 0 {
+		return nil, passwordPolicyError(violations)
+	}
+
 	user, err := u.UserRepository.FindCompleteOneByEmail(req.Username)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the search of the user", err)
@@ -52,19 +64,39 @@ func (u *UserServer) Register(ctx context.Context, req *proto_user.RegisterReque
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	token, err := u.JwtService.Generate(req.Username)
+	created, err := u.UserRepository.FindOneByEmail(req.Username)
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	return &proto_user.RegisterResponse{Token: token, Username: req.Username}, nil
+	dpopJkt, _ := ctx.Value(hooks.ServerContextKey("dpop-jkt")).(string)
+
+	pair, err := u.TokenService.Issue(created, services.IssueOptions{DPoPJkt: dpopJkt, Client: clientInfo(ctx)})
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if u.Cookies.Enabled && req.CookieMode {
+		if err := u.Cookies.SetTokens(ctx, pair.AccessToken, pair.ExpiresAt, pair.RefreshToken, pair.RefreshExpiresAt); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		return &proto_user.RegisterResponse{Username: req.Username, ExpiresAt: pair.ExpiresAt.Unix()}, nil
+	}
+
+	return &proto_user.RegisterResponse{
+		Token:        pair.AccessToken,
+		Username:     req.Username,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt.Unix(),
+	}, nil
 }
 
 // UserServer implements the different servers
 //
 // @route /api/user.UserService/Login
 func (u *UserServer) Ban(ctx context.Context, req *proto_user.BanRequest) (*proto_user.BanResponse, error) {
-	user, err := u.AuthManager.AllowAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	user, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the check of the credentials", err)
 		return nil, twirp.PermissionDenied.Error(err.Error())
@@ -75,12 +107,22 @@ func (u *UserServer) Ban(ctx context.Context, req *proto_user.BanRequest) (*prot
 		return nil, twirp.InvalidArgument.Error("Username is empty")
 	}
 
-	if user.Email != req.Username {
+	target, err := u.UserRepository.FindOneByEmail(req.Username)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if target.Email != req.Username {
 		u.Logger.Sugar().Error("User not found")
 		return nil, twirp.NotFound.Error("User not found")
 	}
 
-	_, err = u.UserRepository.SoftDelete(user.Email)
+	if target.Id == user.Id {
+		return nil, twirp.InvalidArgument.Error("An admin cannot ban itself")
+	}
+
+	// Banning bumps the token version of the user, ending all of its sessions
+	_, err = u.UserRepository.SoftDelete(target.Email)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the soft delete of the user", err)
 		return nil, twirp.InternalErrorWith(err)
@@ -93,7 +135,7 @@ func (u *UserServer) Ban(ctx context.Context, req *proto_user.BanRequest) (*prot
 //
 // @route /api/user.UserService/Unban
 func (u *UserServer) Unban(ctx context.Context, req *proto_user.UnbanRequest) (*proto_user.UnbanResponse, error) {
-	user, err := u.AuthManager.AllowAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	user, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the check of the credentials", err)
 		return nil, twirp.PermissionDenied.Error(err.Error())
@@ -104,17 +146,21 @@ func (u *UserServer) Unban(ctx context.Context, req *proto_user.UnbanRequest) (*
 		return nil, twirp.InvalidArgument.Error("Username is empty")
 	}
 
-	if user.Email != req.Username {
-		u.Logger.Sugar().Error("User not found")
-		return nil, twirp.NotFound.Error("User not found")
+	if user.Email == req.Username {
+		return nil, twirp.InvalidArgument.Error("An admin cannot unban itself")
 	}
 
-	_, err = u.UserRepository.RemoveSoftDelete(user.Email)
+	affected, err := u.UserRepository.RemoveSoftDelete(req.Username)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the soft delete of the user", err)
 		return nil, twirp.InternalErrorWith(err)
 	}
 
+	if affected == 0 {
+		u.Logger.Sugar().Error("Banned user not found")
+		return nil, twirp.NotFound.Error("User not found")
+	}
+
 	return &proto_user.UnbanResponse{Success: true}, nil
 }
 
@@ -122,7 +168,7 @@ func (u *UserServer) Unban(ctx context.Context, req *proto_user.UnbanRequest) (*
 //
 // @route /api/user.UserService/Update
 func (u *UserServer) Delete(ctx context.Context, req *proto_user.DeleteRequest) (*proto_user.DeleteResponse, error) {
-	user, err := u.AuthManager.AllowAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	user, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the check of the credentials", err)
 		return nil, twirp.PermissionDenied.Error(err.Error())
@@ -133,17 +179,22 @@ func (u *UserServer) Delete(ctx context.Context, req *proto_user.DeleteRequest)
 		return nil, twirp.InvalidArgument.Error("Username is empty")
 	}
 
-	if user.Email != req.Username {
-		u.Logger.Sugar().Error("User not found")
-		return nil, twirp.NotFound.Error("User not found")
+	if user.Email == req.Username {
+		return nil, twirp.InvalidArgument.Error("An admin cannot delete itself")
 	}
 
-	_, err = u.UserRepository.HardDelete(user.Id)
+	// The banned users are deleted too, so the user is not looked up among the active ones
+	affected, err := u.UserRepository.HardDelete(req.Username)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the hard delete of the user", err)
 		return nil, twirp.InternalErrorWith(err)
 	}
 
+	if affected == 0 {
+		u.Logger.Sugar().Error("User not found")
+		return nil, twirp.NotFound.Error("User not found")
+	}
+
 	return &proto_user.DeleteResponse{Success: true}, nil
 }
 
@@ -151,7 +202,7 @@ func (u *UserServer) Delete(ctx context.Context, req *proto_user.DeleteRequest)
 //
 // @route /api/user.UserService/Update
 func (u *UserServer) UpdatePassword(ctx context.Context, req *proto_user.UpdatePasswordRequest) (*proto_user.UpdatePasswordResponse, error) {
-	user, err := u.AuthManager.AllowAccessWithRole(ctx, []role.ROLE{})
+	user, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{})
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the check of the credentials", err)
 		return nil, twirp.PermissionDenied.Error(err.Error())
@@ -167,6 +218,15 @@ func (u *UserServer) UpdatePassword(ctx context.Context, req *proto_user.UpdateP
 		return nil, twirp.InvalidArgument.Error("New password is empty")
 	}
 
+	violations, err := u.PasswordPolicy.Check(req.NewPassword, user.Email)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the password policy", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if len(violations) > 0 {
+		return nil, passwordPolicyError(violations)
+	}
+
 	if user.Email != req.Username {
 		u.Logger.Sugar().Error("User not found")
 		return nil, twirp.NotFound.Error("User not found")
@@ -182,6 +242,15 @@ func (u *UserServer) UpdatePassword(ctx context.Context, req *proto_user.UpdateP
 		return nil, twirp.Unauthenticated.Error("Invalid password")
 	}
 
+	err = u.PasswordHistory.Check(user, req.NewPassword)
+	if errors.Is(err, services.ErrPasswordReused) {
+		return nil, passwordReusedError(u.PasswordHistory.Size)
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the password history", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
 	hash, err := u.PasswordService.Hash(req.NewPassword)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the hashing of the password", err)
@@ -194,6 +263,11 @@ func (u *UserServer) UpdatePassword(ctx context.Context, req *proto_user.UpdateP
 		return nil, twirp.InternalErrorWith(err)
 	}
 
+	// The replaced password has been recorded in the history, the oldest ones are no longer compared
+	if err := u.PasswordHistory.Prune(user); err != nil {
+		u.Logger.Sugar().Error("Error during the pruning of the password history", err)
+	}
+
 	return &proto_user.UpdatePasswordResponse{Success: true}, nil
 }
 
@@ -201,7 +275,7 @@ func (u *UserServer) UpdatePassword(ctx context.Context, req *proto_user.UpdateP
 //
 // @route /api/user.UserService/Update
 func (u *UserServer) UpdateEmail(ctx context.Context, req *proto_user.UpdateEmailRequest) (*proto_user.UpdateEmailResponse, error) {
-	user, err := u.AuthManager.AllowAccessWithRole(ctx, []role.ROLE{})
+	user, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{})
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the check of the credentials", err)
 		return nil, twirp.PermissionDenied.Error(err.Error())
@@ -222,11 +296,20 @@ func (u *UserServer) UpdateEmail(ctx context.Context, req *proto_user.UpdateEmai
 		return nil, twirp.NotFound.Error("User not found")
 	}
 
-	if user.Email == req.NewEmail {
-		u.Logger.Sugar().Error("User already exists", user.Email)
-		return nil, twirp.NotFound.Error("User already exists")
+	// The email of a banned user stays taken, the user can be unbanned
+	exists, err := u.UserRepository.ExistsByEmail(req.NewEmail)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the search of the user", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if exists {
+		u.Logger.Sugar().Error("User already exists", req.NewEmail)
+		return nil, twirp.AlreadyExists.Error("User already exists")
 	}
 
+	// The change bumps the token version, the tokens carrying the previous email are refused
+	// and the user has to log in again
 	_, err = u.UserRepository.UpdateEmail(req.OldEmail, req.NewEmail)
 	if err != nil {
 		u.Logger.Sugar().Error("Error during the update of the email", err)
@@ -235,3 +318,232 @@ func (u *UserServer) UpdateEmail(ctx context.Context, req *proto_user.UpdateEmai
 
 	return &proto_user.UpdateEmailResponse{Success: true}, nil
 }
+
+// RevokeAllSessions invalidates every outstanding token of a user, restricted to admins.
+// The user has to log in again on every device.
+//
+// @route /api/user.UserService/RevokeAllSessions
+func (u *UserServer) RevokeAllSessions(ctx context.Context, req *proto_user.RevokeAllSessionsRequest) (*proto_user.RevokeAllSessionsResponse, error) {
+	admin, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
+
+	if req.Username == "" {
+		u.Logger.Sugar().Error("Username is empty")
+		return nil, twirp.InvalidArgument.Error("Username is empty")
+	}
+
+	user, err := u.UserRepository.FindOneByEmail(req.Username)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if user.Email != req.Username {
+		u.Logger.Sugar().Error("User not found")
+		return nil, twirp.NotFound.Error("User not found")
+	}
+
+	if err := u.TokenService.RevokeAllSessions(user); err != nil {
+		u.Logger.Sugar().Error("Error during the revocation of the sessions", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	u.Logger.Sugar().Infof("Every session of user %s revoked by %s", user.Uuuid, admin.Uuuid)
+
+	return &proto_user.RevokeAllSessionsResponse{Success: true}, nil
+}
+
+// Impersonate issues a short-lived token of a user to an admin reproducing an issue as that user.
+// The token carries an act claim naming the admin, the calls made with it are logged,
+// and it is refused by the sensitive RPCs such as UpdatePassword and Delete.
+//
+// @route /api/user.UserService/Impersonate
+func (u *UserServer) Impersonate(ctx context.Context, req *proto_user.ImpersonateRequest) (*proto_user.ImpersonateResponse, error) {
+	admin, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
+
+	if req.Username == "" {
+		u.Logger.Sugar().Error("Username is empty")
+		return nil, twirp.InvalidArgument.Error("Username is empty")
+	}
+
+	user, err := u.UserRepository.FindOneByEmail(req.Username)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if user.Email != req.Username {
+		u.Logger.Sugar().Error("User not found")
+		return nil, twirp.NotFound.Error("User not found")
+	}
+
+	dpopJkt, _ := ctx.Value(hooks.ServerContextKey("dpop-jkt")).(string)
+
+	token, expiresAt, err := u.TokenService.Impersonate(admin, user, u.ImpersonationLifetime, dpopJkt)
+	if errors.Is(err, services.ErrImpersonationForbidden) {
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the impersonation of the user", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	u.Logger.Warn("User impersonated",
+		zap.String("subject", user.Uuuid),
+		zap.String("actor", admin.Uuuid),
+		zap.String("actor_email", admin.Email),
+		zap.Time("expires_at", expiresAt),
+	)
+
+	return &proto_user.ImpersonateResponse{
+		Token:     token,
+		Username:  user.Email,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// RequirePasswordChange forces a user to change its password. Until then, its logins only issue tokens
+// restricted to UpdatePassword. Its current sessions are not revoked.
+//
+// @route /api/user.UserService/RequirePasswordChange
+func (u *UserServer) RequirePasswordChange(ctx context.Context, req *proto_user.RequirePasswordChangeRequest) (*proto_user.RequirePasswordChangeResponse, error) {
+	admin, err := u.AuthManager.AllowDirectAccessWithRole(ctx, []role.ROLE{role.ROLE_ADMIN})
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the credentials", err)
+		return nil, twirp.PermissionDenied.Error(err.Error())
+	}
+
+	if req.Username == "" {
+		u.Logger.Sugar().Error("Username is empty")
+		return nil, twirp.InvalidArgument.Error("Username is empty")
+	}
+
+	user, err := u.UserRepository.FindOneByEmail(req.Username)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if user.Email != req.Username {
+		u.Logger.Sugar().Error("User not found")
+		return nil, twirp.NotFound.Error("User not found")
+	}
+
+	if _, err := u.UserRepository.SetMustChangePassword(user.Id, true); err != nil {
+		u.Logger.Sugar().Error("Error during the update of the user", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	u.Logger.Sugar().Infof("Password change of user %s required by %s", user.Uuuid, admin.Uuuid)
+
+	return &proto_user.RequirePasswordChangeResponse{Success: true}, nil
+}
+
+// RequestPasswordReset sends a single-use link to reset the password to the email of the user.
+// The response is the same whether or not the account exists: the link is sent in the background
+// and the errors are only logged. The requests are throttled per email and per client IP.
+//
+// @route /api/user.UserService/RequestPasswordReset
+func (u *UserServer) RequestPasswordReset(ctx context.Context, req *proto_user.RequestPasswordResetRequest) (*proto_user.RequestPasswordResetResponse, error) {
+	if u.PasswordReset == nil {
+		return nil, twirp.Unimplemented.Error("Password reset is disabled")
+	}
+
+	if req.Username == "" {
+		u.Logger.Sugar().Error("Username is empty")
+		return nil, twirp.InvalidArgument.Error("Username is empty")
+	}
+
+	err := u.PasswordReset.Submit(req.Username, clientInfo(ctx).ClientIp, func(err error) {
+		u.Logger.Sugar().Error("Error during the request of a password reset", err)
+	})
+	if errors.Is(err, services.ErrResetThrottled) {
+		u.Logger.Sugar().Warn("Password reset request throttled")
+		return nil, twirp.ResourceExhausted.Error("Too many password reset requests")
+	}
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &proto_user.RequestPasswordResetResponse{Success: true}, nil
+}
+
+// ConfirmPasswordReset sets the new password of the user of a reset token, under the same policy
+// as a password change. Every session of the user ends with the reset.
+//
+// @route /api/user.UserService/ConfirmPasswordReset
+func (u *UserServer) ConfirmPasswordReset(ctx context.Context, req *proto_user.ConfirmPasswordResetRequest) (*proto_user.ConfirmPasswordResetResponse, error) {
+	if u.PasswordReset == nil {
+		return nil, twirp.Unimplemented.Error("Password reset is disabled")
+	}
+
+	if req.Token == "" {
+		u.Logger.Sugar().Error("Token is empty")
+		return nil, twirp.InvalidArgument.Error("Token is empty")
+	}
+
+	if req.NewPassword == "" {
+		u.Logger.Sugar().Error("New password is empty")
+		return nil, twirp.InvalidArgument.Error("New password is empty")
+	}
+
+	user, reset, err := u.PasswordReset.Verify(req.Token)
+	if errors.Is(err, services.ErrInvalidResetToken) {
+		return nil, twirp.Unauthenticated.Error("Invalid reset token")
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the verification of the reset token", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	violations, err := u.PasswordPolicy.Check(req.NewPassword, user.Email)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the password policy", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if len(violations) > 0 {
+		return nil, passwordPolicyError(violations)
+	}
+
+	err = u.PasswordHistory.Check(user, req.NewPassword)
+	if errors.Is(err, services.ErrPasswordReused) {
+		return nil, passwordReusedError(u.PasswordHistory.Size)
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the check of the password history", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	hash, err := u.PasswordService.Hash(req.NewPassword)
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the hashing of the password", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	// The token is consumed along with the update of the password, it cannot reset the password twice.
+	// The change of the password bumps the token version, invalidating every outstanding token
+	err = u.PasswordReset.Reset(user, reset, hash)
+	if errors.Is(err, services.ErrInvalidResetToken) {
+		return nil, twirp.Unauthenticated.Error("Invalid reset token")
+	}
+	if err != nil {
+		u.Logger.Sugar().Error("Error during the reset of the password", err)
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if err := u.PasswordReset.EndSessions(user); err != nil {
+		u.Logger.Sugar().Error("Error during the revocation of the sessions", err)
+	}
+
+	if err := u.PasswordHistory.Prune(user); err != nil {
+		u.Logger.Sugar().Error("Error during the pruning of the password history", err)
+	}
+
+	u.Logger.Sugar().Infof("Password of user %s reset", user.Uuuid)
+
+	return &proto_user.ConfirmPasswordResetResponse{Success: true}, nil
+}
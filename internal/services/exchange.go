@@ -0,0 +1,43 @@
+package services
+
+import (
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+// ExchangedToken is the token issued by a token exchange.
+type ExchangedToken struct {
+	AccessToken string
+	ExpiresAt   time.Time
+	// Scope is the scope granted to the token, empty if it is not restricted.
+	Scope string
+}
+
+// Exchange verifies a subject access token and issues a downscoped token to the client acting on behalf
+// of its user, as described by RFC 8693. The token expires with the subject token, or earlier with
+// the access token lifetime of the user.
+// Returns ErrInvalidAccessToken if the subject token is not valid, crypto.ErrBoundSubjectToken if it is bound
+// to a DPoP key, or crypto.ErrScopeNotGranted if the delegation requests more than the subject token grants.
+func (t *TokenService) Exchange(subjectToken string, delegation crypto.Delegation) (ExchangedToken, error) {
+	subject, err := t.VerifyAccessToken(subjectToken)
+	if err != nil {
+		return ExchangedToken{}, err
+	}
+
+	if delegation.ExpiresAt.IsZero() {
+		delegation.ExpiresAt = time.Now().Add(t.Lifetimes.Lifetime(crypto.TOKEN_ACCESS, subject.Roles, false))
+	}
+
+	claims, err := crypto.Downscope(subject, delegation)
+	if err != nil {
+		return ExchangedToken{}, err
+	}
+
+	token, err := t.JwtService.Generate(claims)
+	if err != nil {
+		return ExchangedToken{}, err
+	}
+
+	return ExchangedToken{AccessToken: token, ExpiresAt: claims.ExpiresAt.Time, Scope: claims.Scope}, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/auth/role"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// ErrImpersonationForbidden is returned when the target of an impersonation cannot be impersonated.
+var ErrImpersonationForbidden = errors.New("user cannot be impersonated")
+
+// Impersonate issues a short-lived access token of the target user to an admin reproducing an issue as that user.
+// The token carries an act claim naming the admin, has no refresh token nor session, and never outlives
+// an access token of the target. Admins cannot be impersonated, nor can the admin itself.
+//
+// Parameters:
+// - admin: the admin impersonating the user.
+// - target: the impersonated user.
+// - lifetime: the lifetime of the token.
+// - dpopJkt: the thumbprint of the DPoP key of the admin the token is bound to, empty if not bound.
+//
+// Returns:
+// - The access token and its expiration date.
+// - ErrImpersonationForbidden if the target cannot be impersonated, or an error if the generation fails.
+func (t *TokenService) Impersonate(admin dto.User, target dto.User, lifetime time.Duration, dpopJkt string) (string, time.Time, error) {
+	if admin.Uuuid == target.Uuuid || slices.Contains(target.Role, string(role.ROLE_ADMIN)) {
+		return "", time.Time{}, ErrImpersonationForbidden
+	}
+
+	lifetime = min(lifetime, t.Lifetimes.Lifetime(crypto.TOKEN_ACCESS, target.Role, false))
+	expiresAt := time.Now().Add(lifetime)
+
+	claims := crypto.NewClaims(target.Uuuid, target.Email, target.Role)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	claims.TokenVersion = target.TokenVersion
+	claims.Act = &crypto.Actor{Subject: admin.Uuuid, Email: admin.Email}
+	if dpopJkt != "" {
+		claims.Cnf = &crypto.Confirmation{JKT: dpopJkt}
+	}
+
+	token, err := t.JwtService.Generate(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
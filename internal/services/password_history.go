@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// ErrPasswordReused is returned when a new password is one of the last passwords of the user.
+var ErrPasswordReused = errors.New("password has been used recently")
+
+// PasswordHistoryStoreInterface defines the methods required to read and prune the previous password hashes
+// of the users. It is implemented by repository.PasswordHistoryRepository.
+type PasswordHistoryStoreInterface interface {
+	// FindLatest returns the hashes of the previous passwords of the user, the most recent first.
+	FindLatest(userId string, limit int) ([]string, error)
+
+	// Prune deletes the previous passwords of the user beyond the most recent ones to keep.
+	// Returns the number of deleted passwords.
+	Prune(userId string, keep int) (int, error)
+}
+
+// PasswordHistory prevents the users from reusing their last passwords.
+type PasswordHistory struct {
+	Repository      PasswordHistoryStoreInterface
+	PasswordService crypto.PasswordServiceInterface
+	// Size is the number of last passwords that cannot be reused, the current one included. 0 disables the check.
+	Size int
+}
+
+func NewPasswordHistory(r PasswordHistoryStoreInterface, p crypto.PasswordServiceInterface, size int) *PasswordHistory {
+	return &PasswordHistory{
+		Repository:      r,
+		PasswordService: p,
+		Size:            size,
+	}
+}
+
+// Check compares a new password with the current password of the user and its previous ones.
+// The hashes that cannot be verified anymore, such as those of a retired pepper, are skipped.
+// Returns ErrPasswordReused if the password matches one of them.
+func (h *PasswordHistory) Check(user dto.User, password string) error {
+	if h.Size <= 0 {
+		return nil
+	}
+
+	previous := []string{}
+	if h.Size > 1 {
+		var err error
+		previous, err = h.Repository.FindLatest(user.Id, h.Size-1)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, hash := range append([]string{user.Password}, previous...) {
+		match, err := h.PasswordService.Verify(password, hash)
+		if err == nil && match {
+			return ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// Prune deletes the previous passwords of the user that are no longer compared,
+// after the replacement of its password recorded the replaced one.
+// The whole history of the user is deleted when the check is disabled.
+func (h *PasswordHistory) Prune(user dto.User) error {
+	_, err := h.Repository.Prune(user.Id, max(h.Size-1, 0))
+	return err
+}
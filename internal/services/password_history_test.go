@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/internal/services"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// memoryHistoryStore keeps the previous password hashes of a single user in memory, the most recent first.
+type memoryHistoryStore struct {
+	hashes  []string
+	lookups int
+}
+
+func (m *memoryHistoryStore) FindLatest(userId string, limit int) ([]string, error) {
+	m.lookups++
+	if limit < 0 {
+		return nil, errors.New("negative limit")
+	}
+
+	return m.hashes[:min(limit, len(m.hashes))], nil
+}
+
+func (m *memoryHistoryStore) Prune(userId string, keep int) (int, error) {
+	if keep < 0 {
+		return 0, errors.New("negative keep")
+	}
+	if keep >= len(m.hashes) {
+		return 0, nil
+	}
+
+	deleted := len(m.hashes) - keep
+	m.hashes = m.hashes[:keep]
+	return deleted, nil
+}
+
+// newPasswordService hashes with cheap parameters, the tests do not need a strong hash.
+func newPasswordService() *crypto.PasswordService {
+	return &crypto.PasswordService{Params: crypto.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16}}
+}
+
+// newHistory returns a history of the given size holding the hashes of the previous passwords,
+// and the user whose current password is given.
+func newHistory(t *testing.T, size int, current string, previous ...string) (*services.PasswordHistory, *memoryHistoryStore, dto.User) {
+	passwordService := newPasswordService()
+
+	store := &memoryHistoryStore{}
+	for _, password := range previous {
+		hash, err := passwordService.Hash(password)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		store.hashes = append(store.hashes, hash)
+	}
+
+	hash, err := passwordService.Hash(current)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return services.NewPasswordHistory(store, passwordService, size), store, dto.User{Id: "1", Password: hash}
+}
+
+func TestPasswordHistory_RefusesCurrentPassword(t *testing.T) {
+	history, _, user := newHistory(t, 3, "current-password")
+
+	if err := history.Check(user, "current-password"); !errors.Is(err, services.ErrPasswordReused) {
+		t.Errorf("expected ErrPasswordReused, got %v", err)
+	}
+}
+
+func TestPasswordHistory_RefusesPreviousPasswords(t *testing.T) {
+	history, _, user := newHistory(t, 3, "current-password", "previous-password", "older-password", "oldest-password")
+
+	for _, password := range []string{"previous-password", "older-password"} {
+		if err := history.Check(user, password); !errors.Is(err, services.ErrPasswordReused) {
+			t.Errorf("expected ErrPasswordReused for %s, got %v", password, err)
+		}
+	}
+
+	// Only the last 3 passwords, the current one included, are compared
+	if err := history.Check(user, "oldest-password"); err != nil {
+		t.Errorf("expected the oldest password to be accepted, got %v", err)
+	}
+	if err := history.Check(user, "brand-new-password"); err != nil {
+		t.Errorf("expected a new password to be accepted, got %v", err)
+	}
+}
+
+func TestPasswordHistory_Prune(t *testing.T) {
+	history, store, user := newHistory(t, 3, "current-password", "previous-password", "older-password", "oldest-password")
+
+	if err := history.Prune(user); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(store.hashes) != 2 {
+		t.Errorf("expected the 2 previous passwords still compared to be kept, got %d", len(store.hashes))
+	}
+}
+
+func TestPasswordHistory_Disabled(t *testing.T) {
+	history, store, user := newHistory(t, 0, "current-password", "previous-password")
+
+	if err := history.Check(user, "current-password"); err != nil {
+		t.Errorf("expected no check when the history is disabled, got %v", err)
+	}
+	if err := history.Prune(user); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(store.hashes) != 0 {
+		t.Errorf("expected the stored history to be deleted when the history is disabled, got %d", len(store.hashes))
+	}
+	if store.lookups != 0 {
+		t.Errorf("expected no lookup when the history is disabled, got %d", store.lookups)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hhertout/twirp_auth/internal/repository"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/lib/notify"
+	"github.com/hhertout/twirp_auth/lib/ratelimit"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// RESET_DELIVERY_TIMEOUT bounds the delivery of a reset link.
+const RESET_DELIVERY_TIMEOUT = 30 * time.Second
+
+// ErrInvalidResetToken is returned when a password reset token is unknown, already used or expired.
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// ErrResetThrottled is returned when a client requests too many password resets,
+// or when too many reset links are being sent at once.
+var ErrResetThrottled = errors.New("too many password reset requests")
+
+// ResetLimits throttle the password reset requests, so that the endpoint cannot flood an inbox.
+// The requests are counted in memory, by each instance of the service.
+type ResetLimits struct {
+	// PerEmail and PerIp are the requests allowed per email and per client IP within the window, no limit when 0.
+	PerEmail int
+	PerIp    int
+	Window   time.Duration
+	// MaxDeliveries is the number of reset links sent at once, the requests are refused past it.
+	MaxDeliveries int
+}
+
+// PasswordReset lets the users recover their account with a single-use link sent to their email.
+// The tokens of the links are random, stored hashed and expire after the lifetime.
+type PasswordReset struct {
+	Repository        *repository.PasswordResetTokenRepository
+	UserRepository    *repository.UserRepository
+	SessionRepository *repository.SessionRepository
+	// Tokens generates the reset tokens and their hashes, the same way as the refresh tokens.
+	Tokens crypto.RefreshTokenServiceInterface
+	Sender notify.SenderInterface
+	// URL is the page of the client completing the reset, the token is added to its query.
+	URL      *url.URL
+	Lifetime time.Duration
+	// EmailLimiter and IpLimiter throttle the requests per email and per client IP.
+	EmailLimiter *ratelimit.Limiter
+	IpLimiter    *ratelimit.Limiter
+	// deliveries holds a slot per reset link being sent.
+	deliveries chan struct{}
+}
+
+func NewPasswordReset(
+	r *repository.PasswordResetTokenRepository,
+	ur *repository.UserRepository,
+	sr *repository.SessionRepository,
+	tokens crypto.RefreshTokenServiceInterface,
+	sender notify.SenderInterface,
+	resetUrl *url.URL,
+	lifetime time.Duration,
+	limits ResetLimits,
+) *PasswordReset {
+	return &PasswordReset{
+		Repository:        r,
+		UserRepository:    ur,
+		SessionRepository: sr,
+		Tokens:            tokens,
+		Sender:            sender,
+		URL:               resetUrl,
+		Lifetime:          lifetime,
+		EmailLimiter:      ratelimit.NewLimiter(limits.PerEmail, limits.Window),
+		IpLimiter:         ratelimit.NewLimiter(limits.PerIp, limits.Window),
+		deliveries:        make(chan struct{}, max(limits.MaxDeliveries, 1)),
+	}
+}
+
+// Submit sends a reset link to the user of the email in the background, the delivery being bounded
+// by RESET_DELIVERY_TIMEOUT, and reports its failure to onError.
+// The requests past the limit of the email are dropped without error, so that the caller cannot tell
+// whether an email is throttled. The requests past the limit of the client IP, or made while the maximum
+// number of links are being sent, are refused with ErrResetThrottled.
+func (p *PasswordReset) Submit(email string, clientIp string, onError func(error)) error {
+	if !p.IpLimiter.Allow(clientIp) {
+		return ErrResetThrottled
+	}
+	if !p.EmailLimiter.Allow(strings.ToLower(strings.TrimSpace(email))) {
+		return nil
+	}
+
+	select {
+	case p.deliveries <- struct{}{}:
+	default:
+		return ErrResetThrottled
+	}
+
+	go func() {
+		defer func() { <-p.deliveries }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), RESET_DELIVERY_TIMEOUT)
+		defer cancel()
+
+		if err := p.Request(ctx, email); err != nil {
+			onError(err)
+		}
+	}()
+
+	return nil
+}
+
+// Request sends a reset link to the user of the email.
+// Nothing is sent, and no error is returned, when no active user has this email,
+// so the caller cannot tell whether the account exists.
+func (p *PasswordReset) Request(ctx context.Context, email string) error {
+	user, err := p.UserRepository.FindOneByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user.Id == "" || user.Email != email {
+		return nil
+	}
+
+	token, hash, err := p.Tokens.Generate()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(p.Lifetime)
+	if _, err := p.Repository.Create(user.Id, hash, expiresAt); err != nil {
+		return err
+	}
+
+	return p.Sender.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "A password reset has been requested for your account.\n\n" +
+			"Follow this link to choose a new password, it expires on " + expiresAt.UTC().Format(time.RFC1123) + ":\n" +
+			p.link(token) + "\n\n" +
+			"If you did not request it, ignore this message, your password stays unchanged.\n",
+	})
+}
+
+// Verify looks a reset token up without consuming it.
+// Returns the user of the token, or ErrInvalidResetToken if the token cannot be used.
+func (p *PasswordReset) Verify(token string) (dto.User, dto.PasswordResetToken, error) {
+	reset, err := p.Repository.FindOneByHash(p.Tokens.Hash(token))
+	if err != nil {
+		return dto.User{}, dto.PasswordResetToken{}, err
+	}
+	if reset.Id == "" || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return dto.User{}, dto.PasswordResetToken{}, ErrInvalidResetToken
+	}
+
+	user, err := p.UserRepository.FindOneById(reset.UserId)
+	if err != nil {
+		return dto.User{}, dto.PasswordResetToken{}, err
+	}
+	// The user has been banned or deleted since the request
+	if user.Id == "" {
+		return dto.User{}, dto.PasswordResetToken{}, ErrInvalidResetToken
+	}
+
+	return user, reset, nil
+}
+
+// Reset consumes a verified reset token and sets the new password hash of its user at once,
+// the other outstanding tokens of the user are consumed along.
+// Returns ErrInvalidResetToken if the token has been used or has expired since its verification,
+// the password is then left unchanged.
+func (p *PasswordReset) Reset(user dto.User, reset dto.PasswordResetToken, hash string) error {
+	affected, err := p.Repository.ResetPassword(reset.Id, user.Id, hash)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvalidResetToken
+	}
+
+	return nil
+}
+
+// EndSessions revokes every session of the user once its password has been reset.
+// Their tokens are already refused since the change of the password bumped the token version.
+func (p *PasswordReset) EndSessions(user dto.User) error {
+	_, err := p.SessionRepository.RevokeAll(user.Id)
+	return err
+}
+
+// PruneTokens removes the reset tokens that are used or expired.
+// Returns the number of removed tokens.
+func (p *PasswordReset) PruneTokens() (int, error) {
+	return p.Repository.DeleteInactive()
+}
+
+// PruneLimits removes the ended windows of the request limits.
+// Returns the number of removed windows.
+func (p *PasswordReset) PruneLimits() int {
+	return p.EmailLimiter.Prune() + p.IpLimiter.Prune()
+}
+
+// link returns the URL of the page completing the reset with the token.
+func (p *PasswordReset) link(token string) string {
+	link := *p.URL
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
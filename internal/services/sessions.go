@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// ErrSessionNotFound is returned when a session does not exist, is not active or belongs to another user.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListSessions returns the active sessions of the user, the most recently used first.
+func (t *TokenService) ListSessions(user dto.User) ([]dto.Session, error) {
+	return t.SessionRepository.FindActiveByUserId(user.Id)
+}
+
+// RevokeSession revokes a session of the user, its access tokens are refused from then on
+// and its refresh tokens are revoked.
+func (t *TokenService) RevokeSession(user dto.User, sessionId string) error {
+	affected, err := t.SessionRepository.Revoke(user.Id, sessionId)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user but the current one.
+// Returns the number of revoked sessions.
+func (t *TokenService) RevokeOtherSessions(user dto.User, currentSessionId string) (int, error) {
+	return t.SessionRepository.RevokeOthers(user.Id, currentSessionId)
+}
+
+// Logout ends the session of an access token: the access token is revoked, as well as its session
+// and, when provided, the family of the refresh token.
+// An expired access token still ends its session, as long as it is correctly signed.
+// Returns ErrInvalidAccessToken if the access token cannot be verified, or ErrTokenNotOwned
+// if the refresh token was issued to another user, nothing is then revoked.
+func (t *TokenService) Logout(accessToken string, refreshToken string) error {
+	valid, claims, err := t.JwtService.VerifyExpired(accessToken)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAccessToken, err)
+	}
+	if !valid {
+		return ErrInvalidAccessToken
+	}
+
+	user, err := t.UserRepository.FindOneByUuid(claims.Subject)
+	if err != nil {
+		return err
+	}
+	if user.Id == "" {
+		return fmt.Errorf("%w: user not found", ErrInvalidAccessToken)
+	}
+
+	var stored dto.RefreshToken
+	if refreshToken != "" {
+		stored, err = t.RefreshTokenRepository.FindOneByHash(t.RefreshTokenService.Hash(refreshToken))
+		if err != nil {
+			return err
+		}
+		if stored.Id != "" && stored.UserId != user.Id {
+			return ErrTokenNotOwned
+		}
+	}
+
+	if claims.SessionId != "" {
+		if err := t.RevokeSession(user, claims.SessionId); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	// An expired access token is refused anyway, it does not need to be denied
+	if claims.ID != "" && claims.ExpiresAt != nil && time.Now().Before(claims.ExpiresAt.Time) {
+		if _, err := t.RevokedTokenRepository.Create(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if stored.Id != "" {
+		if _, err := t.RefreshTokenRepository.RevokeFamily(stored.FamilyId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PruneSessions removes the sessions that expired or were revoked before the given date.
+// Returns the number of removed sessions.
+func (t *TokenService) PruneSessions(before time.Time) (int, error) {
+	return t.SessionRepository.DeleteInactive(before)
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/internal/repository"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	// The whole family of the token is revoked when it happens.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrInvalidAccessToken is returned when an access token cannot be verified.
+	ErrInvalidAccessToken = errors.New("invalid access token")
+	// ErrTokenNotOwned is returned when a user revokes a token issued to another user.
+	ErrTokenNotOwned = errors.New("token was issued to another user")
+)
+
+// TokenPair holds the tokens handed over to a client after a successful authentication.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is the expiration date of the access token.
+	ExpiresAt time.Time
+	// RefreshExpiresAt is the expiration date of the refresh token.
+	RefreshExpiresAt time.Time
+	// PasswordChangeRequired is set when the access token is restricted to the change of the password.
+	PasswordChangeRequired bool
+}
+
+// Token types reported by the introspection.
+const (
+	TOKEN_TYPE_ACCESS  = "access_token"
+	TOKEN_TYPE_REFRESH = "refresh_token"
+)
+
+// Introspection describes the state of a token, as described by RFC 7662.
+// Claims and TokenType are only set for an active token.
+type Introspection struct {
+	Active    bool
+	TokenType string
+	Claims    crypto.Claims
+}
+
+// TokenService opens sessions and issues their access tokens along with rotating refresh tokens.
+// Their lifetimes are given by the lifetime policy.
+// The users who must change their password only receive access tokens restricted to that change.
+type TokenService struct {
+	UserRepository         *repository.UserRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	RevokedTokenRepository *repository.RevokedTokenRepository
+	SessionRepository      *repository.SessionRepository
+	JwtService             crypto.JWTServiceInterface
+	RefreshTokenService    crypto.RefreshTokenServiceInterface
+	Lifetimes              crypto.LifetimePolicy
+	// PasswordMaxAge is the age from which a password must be changed, 0 for passwords that never expire.
+	PasswordMaxAge time.Duration
+}
+
+func NewTokenService(
+	u *repository.UserRepository,
+	rt *repository.RefreshTokenRepository,
+	rvt *repository.RevokedTokenRepository,
+	s *repository.SessionRepository,
+	jwtService crypto.JWTServiceInterface,
+	refreshService crypto.RefreshTokenServiceInterface,
+	lifetimes crypto.LifetimePolicy,
+) *TokenService {
+	return &TokenService{
+		UserRepository:         u,
+		RefreshTokenRepository: rt,
+		RevokedTokenRepository: rvt,
+		SessionRepository:      s,
+		JwtService:             jwtService,
+		RefreshTokenService:    refreshService,
+		Lifetimes:              lifetimes,
+	}
+}
+
+// ClientInfo describes the device a session is opened from.
+type ClientInfo struct {
+	UserAgent string
+	ClientIp  string
+}
+
+// IssueOptions are the options of the tokens opened by a login.
+type IssueOptions struct {
+	// RememberMe gives the tokens of the family the remember me lifetimes.
+	RememberMe bool
+	// DPoPJkt binds the tokens of the family to the DPoP key with the given thumbprint, when not empty.
+	DPoPJkt string
+	// Client is recorded on the session opened by the login.
+	Client ClientInfo
+}
+
+// RotateOptions are the options of a refresh token rotation.
+type RotateOptions struct {
+	// DPoPJkt is the thumbprint of the key of the DPoP proof sent along with the refresh token, if any.
+	DPoPJkt string
+	// Client updates the device recorded on the session.
+	Client ClientInfo
+}
+
+// Issue opens a new session for the user, generates an access token and opens a new refresh token family.
+func (t *TokenService) Issue(user dto.User, options IssueOptions) (TokenPair, error) {
+	lifetime := t.Lifetimes.Lifetime(crypto.TOKEN_REFRESH, user.Role, options.RememberMe)
+	refreshExpiresAt := time.Now().Add(lifetime)
+
+	sessionId, err := t.SessionRepository.Create(user.Id, options.Client.UserAgent, options.Client.ClientIp, refreshExpiresAt)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	accessToken, expiresAt, err := t.generateAccessToken(user, options, sessionId)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshToken, hash, err := t.RefreshTokenService.Generate()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	var dpopJkt *string
+	if options.DPoPJkt != "" {
+		dpopJkt = &options.DPoPJkt
+	}
+
+	_, err = t.RefreshTokenRepository.CreateFamily(user.Id, sessionId, hash, refreshExpiresAt, options.RememberMe, dpopJkt)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:            accessToken,
+		RefreshToken:           refreshToken,
+		ExpiresAt:              expiresAt,
+		RefreshExpiresAt:       refreshExpiresAt,
+		PasswordChangeRequired: t.PasswordChangeRequired(user),
+	}, nil
+}
+
+// Rotate exchanges a refresh token for a new token pair.
+// The presented refresh token is consumed and the new one joins the same family and session.
+// If the refresh token was already consumed, the whole family is revoked and ErrRefreshTokenReused is returned.
+// The tokens of a family bound to a DPoP key are only rotated with a proof of that key.
+func (t *TokenService) Rotate(refreshToken string, options RotateOptions) (TokenPair, error) {
+	stored, err := t.RefreshTokenRepository.FindOneByHash(t.RefreshTokenService.Hash(refreshToken))
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if stored.Id == "" || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	if stored.DPoPJkt != nil && *stored.DPoPJkt != options.DPoPJkt {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		return TokenPair{}, t.revokeFamily(stored.FamilyId, ErrRefreshTokenReused)
+	}
+
+	if stored.SessionId != nil {
+		active, err := t.SessionRepository.IsActive(*stored.SessionId)
+		if err != nil {
+			return TokenPair{}, err
+		}
+		if !active {
+			return TokenPair{}, t.revokeFamily(stored.FamilyId, ErrInvalidRefreshToken)
+		}
+	}
+
+	affected, err := t.RefreshTokenRepository.MarkAsUsed(stored.Id)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if affected == 0 {
+		// Another request consumed the token in the meantime
+		return TokenPair{}, t.revokeFamily(stored.FamilyId, ErrRefreshTokenReused)
+	}
+
+	user, err := t.UserRepository.FindOneById(stored.UserId)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if user.Id == "" {
+		// The user has been deleted or banned since the family was opened
+		return TokenPair{}, t.revokeFamily(stored.FamilyId, ErrInvalidRefreshToken)
+	}
+
+	family := IssueOptions{RememberMe: stored.RememberMe, Client: options.Client}
+	if stored.DPoPJkt != nil {
+		family.DPoPJkt = *stored.DPoPJkt
+	}
+
+	sessionId := ""
+	if stored.SessionId != nil {
+		sessionId = *stored.SessionId
+	}
+
+	accessToken, expiresAt, err := t.generateAccessToken(user, family, sessionId)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	newRefreshToken, hash, err := t.RefreshTokenService.Generate()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	lifetime := t.Lifetimes.Lifetime(crypto.TOKEN_REFRESH, user.Role, stored.RememberMe)
+	refreshExpiresAt := time.Now().Add(lifetime)
+
+	_, err = t.RefreshTokenRepository.CreateInFamily(user.Id, stored.FamilyId, stored.SessionId, hash, refreshExpiresAt, stored.RememberMe, stored.DPoPJkt)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if sessionId != "" {
+		_, err = t.SessionRepository.Touch(sessionId, options.Client.UserAgent, options.Client.ClientIp, refreshExpiresAt)
+		if err != nil {
+			return TokenPair{}, err
+		}
+	}
+
+	return TokenPair{
+		AccessToken:            accessToken,
+		RefreshToken:           newRefreshToken,
+		ExpiresAt:              expiresAt,
+		RefreshExpiresAt:       refreshExpiresAt,
+		PasswordChangeRequired: t.PasswordChangeRequired(user),
+	}, nil
+}
+
+// VerifyAccessToken verifies an access token and checks that its user still exists,
+// that its token version is current and that its session has not been revoked.
+// The tokens restricted to the change of the password are rejected, they do not grant access to the resources.
+// Returns the claims of the token, or ErrInvalidAccessToken wrapping the reason of the rejection.
+func (t *TokenService) VerifyAccessToken(accessToken string) (crypto.Claims, error) {
+	return t.checkAccessToken(t.JwtService.Verify(accessToken))
+}
+
+// checkAccessToken checks the result of the verification of an access token, then its user, its token version
+// and its session, as described by VerifyAccessToken.
+func (t *TokenService) checkAccessToken(valid bool, claims crypto.Claims, err error) (crypto.Claims, error) {
+	if err != nil {
+		return crypto.Claims{}, fmt.Errorf("%w: %w", ErrInvalidAccessToken, err)
+	}
+	if !valid {
+		return crypto.Claims{}, ErrInvalidAccessToken
+	}
+	if claims.PasswordChangeRequired() {
+		return crypto.Claims{}, fmt.Errorf("%w: password must be changed", ErrInvalidAccessToken)
+	}
+
+	user, err := t.UserRepository.FindOneByUuid(claims.Subject)
+	if err != nil {
+		return crypto.Claims{}, err
+	}
+	if user.Id == "" {
+		return crypto.Claims{}, fmt.Errorf("%w: user not found", ErrInvalidAccessToken)
+	}
+	if err := claims.CheckVersion(user.TokenVersion); err != nil {
+		return crypto.Claims{}, fmt.Errorf("%w: %w", ErrInvalidAccessToken, err)
+	}
+
+	if claims.SessionId != "" {
+		active, err := t.SessionRepository.IsActive(claims.SessionId)
+		if err != nil {
+			return crypto.Claims{}, err
+		}
+		if !active {
+			return crypto.Claims{}, fmt.Errorf("%w: session has been revoked", ErrInvalidAccessToken)
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeAllSessions invalidates every outstanding access and refresh token of the user
+// by bumping its token version.
+func (t *TokenService) RevokeAllSessions(user dto.User) error {
+	_, err := t.UserRepository.IncrementTokenVersion(user.Id)
+	return err
+}
+
+// RevokeAccessToken adds the id of an access token to the denylist until the token expires.
+// Revoking a token that is already revoked is a no-op.
+func (t *TokenService) RevokeAccessToken(accessToken string) error {
+	valid, claims, err := t.JwtService.Verify(accessToken)
+	if errors.Is(err, crypto.ErrTokenRevoked) {
+		return nil
+	}
+	if err != nil || !valid || claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidAccessToken
+	}
+
+	_, err = t.RevokedTokenRepository.Create(claims.ID, claims.ExpiresAt.Time)
+	return err
+}
+
+// Revoke revokes a token of the user without knowing its type, as described by RFC 7009.
+// It is first handled as an access token, then as a refresh token.
+// Unknown tokens are ignored since there is nothing left to revoke.
+// Returns ErrTokenNotOwned if the token was issued to another user, the token is then left untouched.
+func (t *TokenService) Revoke(user dto.User, token string) error {
+	valid, claims, err := t.JwtService.Verify(token)
+	if err == nil && valid {
+		if claims.Subject != user.Uuuid {
+			return ErrTokenNotOwned
+		}
+
+		return t.RevokeAccessToken(token)
+	}
+
+	stored, err := t.RefreshTokenRepository.FindOneByHash(t.RefreshTokenService.Hash(token))
+	if err != nil {
+		return err
+	}
+	if stored.Id == "" {
+		return nil
+	}
+	if stored.UserId != user.Id {
+		return ErrTokenNotOwned
+	}
+
+	_, err = t.RefreshTokenRepository.RevokeFamily(stored.FamilyId)
+	return err
+}
+
+// Introspect returns the state of a token issued by the service, either an access token or a refresh token.
+// The hint, "access_token" or "refresh_token", sets which type is looked up first.
+// Unknown, expired and revoked tokens are reported as inactive.
+func (t *TokenService) Introspect(token string, hint string) (Introspection, error) {
+	if hint == TOKEN_TYPE_REFRESH {
+		introspection, err := t.introspectRefreshToken(token)
+		if err != nil || introspection.Active {
+			return introspection, err
+		}
+		return t.introspectAccessToken(token)
+	}
+
+	introspection, err := t.introspectAccessToken(token)
+	if err != nil || introspection.Active {
+		return introspection, err
+	}
+
+	return t.introspectRefreshToken(token)
+}
+
+// PruneRevokedTokens removes the expired tokens from the denylist.
+// Returns the number of removed entries.
+func (t *TokenService) PruneRevokedTokens() (int, error) {
+	return t.RevokedTokenRepository.DeleteExpired()
+}
+
+// PasswordChangeRequired reports whether the user must change its password before using the service:
+// an admin flagged the account, or the password is older than the maximum age.
+func (t *TokenService) PasswordChangeRequired(user dto.User) bool {
+	if user.MustChangePassword {
+		return true
+	}
+
+	return t.PasswordMaxAge > 0 && !user.PasswordChangedAt.IsZero() && time.Since(user.PasswordChangedAt) > t.PasswordMaxAge
+}
+
+// generateAccessToken generates an access token of the session for the user, expiring according to the lifetime policy.
+// The token is bound to the DPoP key of the options, when set.
+// Returns the token and its expiration date.
+func (t *TokenService) generateAccessToken(user dto.User, options IssueOptions, sessionId string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(t.Lifetimes.Lifetime(crypto.TOKEN_ACCESS, user.Role, options.RememberMe))
+
+	claims := crypto.NewClaims(user.Uuuid, user.Email, user.Role)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	claims.TokenVersion = user.TokenVersion
+	claims.SessionId = sessionId
+	if t.PasswordChangeRequired(user) {
+		claims.Scope = crypto.SCOPE_PASSWORD_CHANGE
+	}
+	if options.DPoPJkt != "" {
+		claims.Cnf = &crypto.Confirmation{JKT: options.DPoPJkt}
+	}
+
+	token, err := t.JwtService.Generate(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// introspectAccessToken returns the state of an access token.
+// The tokens issued for other audiences, by a token exchange, are introspected by the services they target:
+// the signature, the expiration, the issuer, the revocation and the user are checked, but not the audience.
+func (t *TokenService) introspectAccessToken(token string) (Introspection, error) {
+	claims, err := t.checkAccessToken(t.JwtService.VerifyAnyAudience(token))
+	if errors.Is(err, ErrInvalidAccessToken) {
+		return Introspection{}, nil
+	}
+	if err != nil {
+		return Introspection{}, err
+	}
+
+	return Introspection{Active: true, TokenType: TOKEN_TYPE_ACCESS, Claims: claims}, nil
+}
+
+// introspectRefreshToken returns the state of a refresh token.
+// A consumed refresh token is inactive, as well as the tokens of a deleted or banned user.
+func (t *TokenService) introspectRefreshToken(token string) (Introspection, error) {
+	stored, err := t.RefreshTokenRepository.FindOneByHash(t.RefreshTokenService.Hash(token))
+	if err != nil {
+		return Introspection{}, err
+	}
+
+	if stored.Id == "" || stored.UsedAt != nil || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return Introspection{}, nil
+	}
+
+	user, err := t.UserRepository.FindOneById(stored.UserId)
+	if err != nil {
+		return Introspection{}, err
+	}
+	if user.Id == "" {
+		return Introspection{}, nil
+	}
+
+	if stored.SessionId != nil {
+		active, err := t.SessionRepository.IsActive(*stored.SessionId)
+		if err != nil || !active {
+			return Introspection{}, err
+		}
+	}
+
+	claims := crypto.NewClaims(user.Uuuid, user.Email, user.Role)
+	claims.TokenVersion = user.TokenVersion
+	if stored.SessionId != nil {
+		claims.SessionId = *stored.SessionId
+	}
+	claims.IssuedAt = jwt.NewNumericDate(stored.CreatedAt)
+	claims.ExpiresAt = jwt.NewNumericDate(stored.ExpiresAt)
+	if stored.DPoPJkt != nil {
+		claims.Cnf = &crypto.Confirmation{JKT: *stored.DPoPJkt}
+	}
+
+	return Introspection{Active: true, TokenType: TOKEN_TYPE_REFRESH, Claims: claims}, nil
+}
+
+// revokeFamily revokes a refresh token family and returns the reason of the revocation,
+// or the error that prevented it.
+func (t *TokenService) revokeFamily(familyId string, reason error) error {
+	if _, err := t.RefreshTokenRepository.RevokeFamily(familyId); err != nil {
+		return err
+	}
+
+	return reason
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration reads a duration from an environment variable, such as "15m" or "720h".
+// Returns the fallback if the variable is not set, or an error if it cannot be parsed.
+func Duration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("env variable %s is not a valid duration: %w", key, err)
+	}
+
+	return d, nil
+}
+
+// Int reads an integer from an environment variable.
+// Returns the fallback if the variable is not set, or an error if it cannot be parsed.
+func Int(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("env variable %s is not a valid integer: %w", key, err)
+	}
+
+	return i, nil
+}
+
+// Bool reads a boolean from an environment variable, accepting the values understood by strconv.ParseBool.
+// Returns the fallback if the variable is not set, or an error if it cannot be parsed.
+func Bool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("env variable %s is not a valid boolean: %w", key, err)
+	}
+
+	return b, nil
+}
+
+// List reads a comma separated list from an environment variable.
+// Blank items are dropped and the remaining ones are trimmed.
+func List(key string) []string {
+	result := []string{}
+
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+func TestDuration(t *testing.T) {
+	os.Setenv("TEST_DURATION", "15m")
+	defer os.Unsetenv("TEST_DURATION")
+
+	d, err := config.Duration("TEST_DURATION", time.Hour)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if d != 15*time.Minute {
+		t.Errorf("expected 15m, got %v", d)
+	}
+}
+
+func TestDuration_Fallback(t *testing.T) {
+	os.Unsetenv("TEST_DURATION")
+
+	d, err := config.Duration("TEST_DURATION", time.Hour)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if d != time.Hour {
+		t.Errorf("expected 1h, got %v", d)
+	}
+}
+
+func TestDuration_Invalid(t *testing.T) {
+	os.Setenv("TEST_DURATION", "fifteen minutes")
+	defer os.Unsetenv("TEST_DURATION")
+
+	_, err := config.Duration("TEST_DURATION", time.Hour)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestInt(t *testing.T) {
+	os.Setenv("TEST_INT", "42")
+	defer os.Unsetenv("TEST_INT")
+
+	i, err := config.Int("TEST_INT", 1)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if i != 42 {
+		t.Errorf("expected 42, got %v", i)
+	}
+}
+
+func TestInt_Invalid(t *testing.T) {
+	os.Setenv("TEST_INT", "forty-two")
+	defer os.Unsetenv("TEST_INT")
+
+	_, err := config.Int("TEST_INT", 1)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestBool(t *testing.T) {
+	os.Setenv("TEST_BOOL", "true")
+	defer os.Unsetenv("TEST_BOOL")
+
+	b, err := config.Bool("TEST_BOOL", false)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !b {
+		t.Errorf("expected true, got false")
+	}
+}
+
+func TestList(t *testing.T) {
+	os.Setenv("TEST_LIST", " a, b ,,c ")
+	defer os.Unsetenv("TEST_LIST")
+
+	result := config.List("TEST_LIST")
+	expected := []string{"a", "b", "c"}
+
+	if len(result) != len(expected) {
+		t.Fatalf("expected length %d, got %d", len(expected), len(result))
+	}
+	for i, v := range result {
+		if v != expected[i] {
+			t.Errorf("expected %s at index %d, got %s", expected[i], i, v)
+		}
+	}
+}
+
+func TestList_Empty(t *testing.T) {
+	os.Unsetenv("TEST_LIST")
+
+	result := config.List("TEST_LIST")
+	if result == nil || len(result) != 0 {
+		t.Errorf("expected empty list, got %v", result)
+	}
+}
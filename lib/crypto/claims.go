@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// Claims are the claims carried by the issued tokens.
+// The subject (sub) is the uuid of the user, the email and the roles are custom claims.
+// The scope and the client id are only set on tokens restricted to a client,
+// the confirmation (cnf) only on tokens bound to a DPoP key, the actor (act) only on tokens
+// issued by a token exchange.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email    string        `json:"email,omitempty"`
+	Roles    []string      `json:"roles,omitempty"`
+	Scope    string        `json:"scope,omitempty"`
+	ClientId string        `json:"client_id,omitempty"`
+	Cnf      *Confirmation `json:"cnf,omitempty"`
+	// TokenVersion is the token version of the user when the token was issued.
+	TokenVersion int `json:"token_version,omitempty"`
+	// SessionId is the session the token belongs to, revoking the session invalidates the token.
+	SessionId string `json:"sid,omitempty"`
+	// Act names the client acting on behalf of the subject, as described by RFC 8693 section 4.1.
+	Act *Actor `json:"act,omitempty"`
+}
+
+// Actor is the party acting on behalf of the subject of a token.
+// The actor of a token exchanged again is nested, the most recent actor first.
+// The email is only set when the actor is a user, such as an admin impersonating the subject.
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+	Act     *Actor `json:"act,omitempty"`
+}
+
+// SCOPE_PASSWORD_CHANGE is the scope of the restricted tokens issued to the users who must change their password,
+// only allowed to change it.
+const SCOPE_PASSWORD_CHANGE = "password_change"
+
+// ErrTokenVersionStale is returned when the token version of the user was bumped after the token was issued.
+var ErrTokenVersionStale = errors.New("token has been invalidated")
+
+// CheckVersion compares the token version of the claims to the current token version of the user.
+// Returns ErrTokenVersionStale if the version has been bumped since the token was issued.
+func (c Claims) CheckVersion(current int) error {
+	if c.TokenVersion != current {
+		return ErrTokenVersionStale
+	}
+
+	return nil
+}
+
+// PasswordChangeRequired reports whether the token is restricted to the change of the password of its user.
+func (c Claims) PasswordChangeRequired() bool {
+	return c.Scope == SCOPE_PASSWORD_CHANGE
+}
+
+// BoundKey returns the thumbprint of the DPoP key the token is bound to, empty if the token is not bound.
+func (c Claims) BoundKey() string {
+	if c.Cnf == nil {
+		return ""
+	}
+
+	return c.Cnf.JKT
+}
+
+// NewClaims creates the claims of a token issued for a user.
+// The remaining registered claims are filled by the service generating the token.
+func NewClaims(uuid string, email string, roles []string) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid},
+		Email:            email,
+		Roles:            roles,
+	}
+}
+
+// TokenConfig holds the registered claims shared by every issued token and how they are verified.
+type TokenConfig struct {
+	// Issuer is written in the iss claim and required when verifying, if not empty.
+	Issuer string
+	// Audience is written in the aud claim and required when verifying, if not empty.
+	Audience string
+	// Leeway is the clock skew tolerated when checking the exp, nbf and iat claims.
+	Leeway time.Duration
+
+	// allowExpired accepts the expired tokens, their other claims are still checked.
+	allowExpired bool
+}
+
+// NewTokenConfigFromEnv reads the token configuration from the "JWT_ISSUER", "JWT_AUDIENCE"
+// and "JWT_LEEWAY" environment variables. The leeway defaults to 30 seconds.
+func NewTokenConfigFromEnv() (TokenConfig, error) {
+	leeway, err := config.Duration("JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return TokenConfig{}, err
+	}
+
+	return TokenConfig{
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		Leeway:   leeway,
+	}, nil
+}
+
+// prepareClaims fills the registered claims managed by the service:
+// token id, issue date, issuer and audience. The expiration date defaults to AccessTokenLifetime
+// when the caller did not set it.
+func (c TokenConfig) prepareClaims(claims Claims) (Claims, error) {
+	jti, err := newTokenId()
+	if err != nil {
+		return Claims{}, err
+	}
+
+	now := time.Now()
+
+	claims.ID = jti
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(AccessTokenLifetime))
+	}
+	if c.Issuer != "" {
+		claims.Issuer = c.Issuer
+	}
+	if c.Audience != "" && len(claims.Audience) == 0 {
+		claims.Audience = jwt.ClaimStrings{c.Audience}
+	}
+
+	return claims, nil
+}
+
+// anyAudience returns the configuration accepting the tokens issued for any audience.
+func (c TokenConfig) anyAudience() TokenConfig {
+	c.Audience = ""
+	return c
+}
+
+// expired returns the configuration accepting the expired tokens.
+func (c TokenConfig) expired() TokenConfig {
+	c.allowExpired = true
+	return c
+}
+
+// validate checks the registered claims of a token whose signature or integrity has been verified.
+// When the expired tokens are accepted, the expiration must still be set but is not checked.
+func (c TokenConfig) validate(claims Claims) error {
+	if c.allowExpired {
+		if claims.ExpiresAt == nil {
+			return fmt.Errorf("%w: exp claim is required", jwt.ErrTokenRequiredClaimMissing)
+		}
+		claims.ExpiresAt = nil
+	}
+
+	return jwt.NewValidator(c.parserOptions(nil)...).Validate(claims)
+}
+
+// parserOptions returns the options enforcing the configuration when parsing a token.
+func (c TokenConfig) parserOptions(methods []string) []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(c.Leeway),
+	}
+	if !c.allowExpired {
+		options = append(options, jwt.WithExpirationRequired())
+	}
+	if c.Issuer != "" {
+		options = append(options, jwt.WithIssuer(c.Issuer))
+	}
+	if c.Audience != "" {
+		options = append(options, jwt.WithAudience(c.Audience))
+	}
+
+	return options
+}
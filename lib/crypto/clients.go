@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// Client is a service allowed to call the machine to machine endpoints, such as the token introspection.
+// Only the SHA-256 hashes of its secret and API key are kept, both are optional.
+type Client struct {
+	Id         string
+	SecretHash []byte
+	ApiKeyHash []byte
+}
+
+// ClientRegistry holds the registered clients and authenticates them.
+type ClientRegistry struct {
+	clients map[string]Client
+}
+
+// NewClientRegistry creates a registry of the given clients.
+func NewClientRegistry(clients ...Client) *ClientRegistry {
+	registry := &ClientRegistry{clients: map[string]Client{}}
+	for _, client := range clients {
+		registry.clients[client.Id] = client
+	}
+
+	return registry
+}
+
+// NewClientRegistryFromEnv reads the registered clients from the environment.
+// "OAUTH_CLIENTS" lists the client credentials and "OAUTH_API_KEYS" the API keys,
+// both as comma separated "<client id>:<hex encoded SHA-256 hash>" pairs.
+func NewClientRegistryFromEnv() (*ClientRegistry, error) {
+	registry := NewClientRegistry()
+
+	secrets, err := parseClientHashes("OAUTH_CLIENTS")
+	if err != nil {
+		return nil, err
+	}
+	for id, hash := range secrets {
+		client := registry.clients[id]
+		client.Id, client.SecretHash = id, hash
+		registry.clients[id] = client
+	}
+
+	apiKeys, err := parseClientHashes("OAUTH_API_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	for id, hash := range apiKeys {
+		client := registry.clients[id]
+		client.Id, client.ApiKeyHash = id, hash
+		registry.clients[id] = client
+	}
+
+	return registry, nil
+}
+
+// HashClientSecret returns the hex encoded SHA-256 hash of a client secret or an API key,
+// as expected in the environment.
+func HashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Empty reports whether no client is registered.
+func (c *ClientRegistry) Empty() bool {
+	return len(c.clients) == 0
+}
+
+// Authenticate checks the credentials of a client.
+// Returns the client and a boolean indicating if the credentials are valid.
+func (c *ClientRegistry) Authenticate(clientId string, secret string) (Client, bool) {
+	client, ok := c.clients[clientId]
+	if !ok || client.SecretHash == nil || !matchHash(client.SecretHash, secret) {
+		return Client{}, false
+	}
+
+	return client, true
+}
+
+// AuthenticateApiKey looks up the client owning an API key.
+// Returns the client and a boolean indicating if the key is valid.
+func (c *ClientRegistry) AuthenticateApiKey(apiKey string) (Client, bool) {
+	if apiKey == "" {
+		return Client{}, false
+	}
+
+	for _, client := range c.clients {
+		if client.ApiKeyHash != nil && matchHash(client.ApiKeyHash, apiKey) {
+			return client, true
+		}
+	}
+
+	return Client{}, false
+}
+
+// AuthenticateRequest authenticates the client calling an endpoint with, in order:
+// - an API key in the "X-API-Key" header,
+// - HTTP Basic client credentials, form encoded as described by RFC 6749 section 2.3.1,
+// - "client_id" and "client_secret" form parameters.
+// Returns the client and a boolean indicating if the client is authenticated.
+func (c *ClientRegistry) AuthenticateRequest(r *http.Request) (Client, bool) {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		return c.AuthenticateApiKey(apiKey)
+	}
+
+	if id, secret, ok := r.BasicAuth(); ok {
+		id, idErr := url.QueryUnescape(id)
+		secret, secretErr := url.QueryUnescape(secret)
+		if idErr != nil || secretErr != nil {
+			return Client{}, false
+		}
+		return c.Authenticate(id, secret)
+	}
+
+	if id := r.PostFormValue("client_id"); id != "" {
+		return c.Authenticate(id, r.PostFormValue("client_secret"))
+	}
+
+	return Client{}, false
+}
+
+// matchHash compares the hash of a secret with a stored hash in constant time.
+func matchHash(hash []byte, secret string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	return subtle.ConstantTimeCompare(hash, sum[:]) == 1
+}
+
+// parseClientHashes reads "<client id>:<hex encoded hash>" pairs from an environment variable.
+func parseClientHashes(key string) (map[string][]byte, error) {
+	result := map[string][]byte{}
+
+	for _, item := range config.List(key) {
+		id, encoded, found := strings.Cut(item, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("env variable %s must contain <client id>:<sha256 hash> pairs", key)
+		}
+
+		hash, err := hex.DecodeString(encoded)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("env variable %s has an invalid hash for client %s", key, id)
+		}
+
+		result[id] = hash
+	}
+
+	return result, nil
+}
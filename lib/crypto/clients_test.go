@@ -0,0 +1,78 @@
+package crypto_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func newClientRegistry(t *testing.T) *crypto.ClientRegistry {
+	os.Setenv("OAUTH_CLIENTS", "gateway:"+crypto.HashClientSecret("gateway secret"))
+	os.Setenv("OAUTH_API_KEYS", "billing:"+crypto.HashClientSecret("billing-key"))
+	defer os.Unsetenv("OAUTH_CLIENTS")
+	defer os.Unsetenv("OAUTH_API_KEYS")
+
+	registry, err := crypto.NewClientRegistryFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return registry
+}
+
+func TestClientRegistry_Authenticate(t *testing.T) {
+	registry := newClientRegistry(t)
+
+	if client, ok := registry.Authenticate("gateway", "gateway secret"); !ok || client.Id != "gateway" {
+		t.Errorf("expected gateway to be authenticated")
+	}
+	if _, ok := registry.Authenticate("gateway", "wrong"); ok {
+		t.Errorf("expected wrong secret to be rejected")
+	}
+	if _, ok := registry.Authenticate("billing", ""); ok {
+		t.Errorf("expected client without secret to be rejected")
+	}
+	if client, ok := registry.AuthenticateApiKey("billing-key"); !ok || client.Id != "billing" {
+		t.Errorf("expected billing to be authenticated by its API key")
+	}
+}
+
+func TestClientRegistry_AuthenticateRequest(t *testing.T) {
+	registry := newClientRegistry(t)
+
+	basic := httptest.NewRequest("POST", "/introspect", nil)
+	basic.SetBasicAuth("gateway", url.QueryEscape("gateway secret"))
+	if client, ok := registry.AuthenticateRequest(basic); !ok || client.Id != "gateway" {
+		t.Errorf("expected basic credentials to be accepted")
+	}
+
+	apiKey := httptest.NewRequest("POST", "/introspect", nil)
+	apiKey.Header.Set("X-API-Key", "billing-key")
+	if client, ok := registry.AuthenticateRequest(apiKey); !ok || client.Id != "billing" {
+		t.Errorf("expected API key to be accepted")
+	}
+
+	form := httptest.NewRequest("POST", "/introspect", strings.NewReader("client_id=gateway&client_secret=gateway+secret"))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if client, ok := registry.AuthenticateRequest(form); !ok || client.Id != "gateway" {
+		t.Errorf("expected form credentials to be accepted")
+	}
+
+	anonymous := httptest.NewRequest("POST", "/introspect", nil)
+	if _, ok := registry.AuthenticateRequest(anonymous); ok {
+		t.Errorf("expected anonymous request to be rejected")
+	}
+}
+
+func TestClientRegistry_InvalidEnv(t *testing.T) {
+	os.Setenv("OAUTH_CLIENTS", "gateway:not-a-hash")
+	defer os.Unsetenv("OAUTH_CLIENTS")
+
+	if _, err := crypto.NewClientRegistryFromEnv(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,223 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DPOP_PROOF_TYPE is the "typ" header required on DPoP proofs.
+const DPOP_PROOF_TYPE = "dpop+jwt"
+
+var (
+	// ErrInvalidDPoPProof is returned when a DPoP proof is malformed, badly signed or does not match the request.
+	ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+	// ErrDPoPProofReplayed is returned when the id of a DPoP proof has already been used.
+	ErrDPoPProofReplayed = errors.New("DPoP proof has already been used")
+)
+
+// Confirmation is the "cnf" claim binding a token to a key, as described by RFC 7800.
+// JKT is the RFC 7638 thumbprint of the public key of the client, as described by RFC 9449.
+type Confirmation struct {
+	JKT string `json:"jkt,omitempty"`
+}
+
+// DPoPClaims are the claims of a DPoP proof.
+// The proof is bound to an access token by the "ath" claim, the hash of the token.
+type DPoPClaims struct {
+	jwt.RegisteredClaims
+	Htm string `json:"htm"`
+	Htu string `json:"htu"`
+	Ath string `json:"ath,omitempty"`
+}
+
+// ReplayCacheInterface defines the methods required to detect a reused proof id.
+type ReplayCacheInterface interface {
+	// Seen records the id until the given date, and reports whether it was already recorded.
+	Seen(id string, until time.Time) bool
+}
+
+// MemoryReplayCache is an in memory ReplayCacheInterface.
+// Each instance of the service keeps its own cache: a proof replayed against another replica is not detected,
+// unless the requests of a client are always routed to the same instance.
+// The expired ids are only removed by Prune, which is expected to run periodically.
+type MemoryReplayCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryReplayCache creates an empty replay cache.
+// Returns a pointer to the newly created MemoryReplayCache.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{entries: map[string]time.Time{}}
+}
+
+func (c *MemoryReplayCache) Seen(id string, until time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// An expired id that has not been pruned yet is recorded again
+	if expiresAt, ok := c.entries[id]; ok && !time.Now().After(expiresAt) {
+		return true
+	}
+	c.entries[id] = until
+
+	return false
+}
+
+// Prune removes the expired ids.
+// Returns the number of removed ids.
+func (c *MemoryReplayCache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	pruned := 0
+	for id, expiresAt := range c.entries {
+		if now.After(expiresAt) {
+			delete(c.entries, id)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+// DPoPVerifier validates the DPoP proofs sent along with the requests, as described by RFC 9449.
+type DPoPVerifier struct {
+	// MaxAge is how long after its issue date a proof is accepted.
+	MaxAge time.Duration
+	// Leeway is the clock skew tolerated on the issue date of a proof.
+	Leeway time.Duration
+	// Replay records the ids of the accepted proofs so that they cannot be used twice.
+	Replay ReplayCacheInterface
+}
+
+// NewDPoPVerifier creates a DPoPVerifier accepting proofs issued in the last maxAge.
+// Returns a pointer to the newly created DPoPVerifier.
+func NewDPoPVerifier(maxAge time.Duration, leeway time.Duration, replay ReplayCacheInterface) *DPoPVerifier {
+	return &DPoPVerifier{MaxAge: maxAge, Leeway: leeway, Replay: replay}
+}
+
+// Verify checks a DPoP proof against the request it was sent with.
+//
+// Parameters:
+// - proof: the value of the "DPoP" header.
+// - method: the HTTP method of the request, compared to the "htm" claim.
+// - uri: the URL of the request, compared to the "htu" claim without query nor fragment.
+// - accessToken: the access token sent with the proof, its hash must match the "ath" claim. Empty when no token is sent.
+//
+// Returns:
+// - The RFC 7638 thumbprint of the key signing the proof, to compare with the "cnf.jkt" claim of the access token.
+// - An error if the proof is invalid or replayed.
+func (d *DPoPVerifier) Verify(proof string, method string, uri string, accessToken string) (string, error) {
+	var claims DPoPClaims
+	var thumbprint string
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{ALG_RS256, ALG_ES256, ALG_EDDSA}),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(d.Leeway),
+	)
+	_, err := parser.ParseWithClaims(proof, &claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != DPOP_PROOF_TYPE {
+			return nil, errors.New("proof is not a dpop+jwt")
+		}
+
+		key, err := headerJWK(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := key.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		thumbprint, err = key.Thumbprint()
+		if err != nil {
+			return nil, err
+		}
+
+		return public, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidDPoPProof, err)
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return "", fmt.Errorf("%w: jti and iat claims are required", ErrInvalidDPoPProof)
+	}
+	if time.Since(claims.IssuedAt.Time) > d.MaxAge+d.Leeway {
+		return "", fmt.Errorf("%w: proof is too old", ErrInvalidDPoPProof)
+	}
+	if !strings.EqualFold(claims.Htm, method) {
+		return "", fmt.Errorf("%w: htm does not match the request method", ErrInvalidDPoPProof)
+	}
+	if !sameHTTPURI(claims.Htu, uri) {
+		return "", fmt.Errorf("%w: htu does not match the request URL", ErrInvalidDPoPProof)
+	}
+	if accessToken != "" && claims.Ath != AccessTokenHash(accessToken) {
+		return "", fmt.Errorf("%w: ath does not match the access token", ErrInvalidDPoPProof)
+	}
+
+	if d.Replay != nil && d.Replay.Seen(thumbprint+":"+claims.ID, claims.IssuedAt.Add(d.MaxAge+2*d.Leeway)) {
+		return "", ErrDPoPProofReplayed
+	}
+
+	return thumbprint, nil
+}
+
+// AccessTokenHash returns the base64url encoded SHA-256 hash of an access token, as written in the "ath" claim.
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// headerJWK reads the public key embedded in the "jwk" header of a proof.
+// A key holding private members is refused.
+func headerJWK(header any) (JWK, error) {
+	members, ok := header.(map[string]any)
+	if !ok {
+		return JWK{}, errors.New("proof has no jwk header")
+	}
+	if _, private := members["d"]; private {
+		return JWK{}, errors.New("jwk header holds a private key")
+	}
+
+	content, err := json.Marshal(members)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	var key JWK
+	if err := json.Unmarshal(content, &key); err != nil {
+		return JWK{}, err
+	}
+
+	return key, nil
+}
+
+// sameHTTPURI compares two URLs ignoring their query and fragment, as well as the case of the scheme and host.
+func sameHTTPURI(a string, b string) bool {
+	first, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	second, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(first.Scheme, second.Scheme) &&
+		strings.EqualFold(first.Host, second.Host) &&
+		first.EscapedPath() == second.EscapedPath()
+}
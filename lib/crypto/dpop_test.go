@@ -0,0 +1,144 @@
+package crypto_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+const testDPoPURI = "https://auth.example.com/api/auth.AuthenticationService/Login"
+
+func newDPoPProof(t *testing.T, private ed25519.PrivateKey, claims crypto.DPoPClaims) string {
+	jwk, err := crypto.PublicJWK(private.Public())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["typ"] = crypto.DPOP_PROOF_TYPE
+	token.Header["jwk"] = jwk
+
+	proof, err := token.SignedString(private)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return proof
+}
+
+func newDPoPClaims(jti string, htu string, ath string) crypto.DPoPClaims {
+	return crypto.DPoPClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: jti, IssuedAt: jwt.NewNumericDate(time.Now())},
+		Htm:              "POST",
+		Htu:              htu,
+		Ath:              ath,
+	}
+}
+
+func TestDPoP_Verify(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	expected, _ := crypto.Thumbprint(public)
+	verifier := crypto.NewDPoPVerifier(time.Minute, 5*time.Second, crypto.NewMemoryReplayCache())
+
+	proof := newDPoPProof(t, private, newDPoPClaims("1", testDPoPURI, crypto.AccessTokenHash("token")))
+	thumbprint, err := verifier.Verify(proof, "POST", testDPoPURI+"?query", "token")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if thumbprint != expected {
+		t.Errorf("expected thumbprint %v, got %v", expected, thumbprint)
+	}
+
+	if _, err := verifier.Verify(proof, "POST", testDPoPURI, "token"); !errors.Is(err, crypto.ErrDPoPProofReplayed) {
+		t.Errorf("expected ErrDPoPProofReplayed, got %v", err)
+	}
+}
+
+func TestDPoP_Verify_Mismatch(t *testing.T) {
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	verifier := crypto.NewDPoPVerifier(time.Minute, 5*time.Second, crypto.NewMemoryReplayCache())
+
+	cases := map[string]func() (string, string, string){
+		"method": func() (string, string, string) {
+			return newDPoPProof(t, private, newDPoPClaims("method", testDPoPURI, "")), "GET", ""
+		},
+		"url": func() (string, string, string) {
+			return newDPoPProof(t, private, newDPoPClaims("url", "https://other.example.com/", "")), "POST", ""
+		},
+		"ath": func() (string, string, string) {
+			return newDPoPProof(t, private, newDPoPClaims("ath", testDPoPURI, crypto.AccessTokenHash("other"))), "POST", "token"
+		},
+		"iat": func() (string, string, string) {
+			claims := newDPoPClaims("iat", testDPoPURI, "")
+			claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			return newDPoPProof(t, private, claims), "POST", ""
+		},
+	}
+
+	for name, build := range cases {
+		proof, method, token := build()
+		if _, err := verifier.Verify(proof, method, testDPoPURI, token); !errors.Is(err, crypto.ErrInvalidDPoPProof) {
+			t.Errorf("%s: expected ErrInvalidDPoPProof, got %v", name, err)
+		}
+	}
+}
+
+func TestDPoP_Verify_RequiresProofType(t *testing.T) {
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	jwk, _ := crypto.PublicJWK(private.Public())
+	verifier := crypto.NewDPoPVerifier(time.Minute, 0, nil)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, newDPoPClaims("typ", testDPoPURI, ""))
+	token.Header["jwk"] = jwk
+	proof, _ := token.SignedString(private)
+
+	if _, err := verifier.Verify(proof, "POST", testDPoPURI, ""); !errors.Is(err, crypto.ErrInvalidDPoPProof) {
+		t.Errorf("expected ErrInvalidDPoPProof, got %v", err)
+	}
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	for _, algorithm := range []string{crypto.ALG_RS256, crypto.ALG_ES256, crypto.ALG_EDDSA} {
+		key, _ := crypto.GenerateSigningKey(algorithm)
+		jwk, _ := crypto.PublicJWK(key.Public)
+
+		public, err := jwk.PublicKey()
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", algorithm, err)
+		}
+
+		expected, _ := crypto.Thumbprint(key.Public)
+		if thumbprint, _ := crypto.Thumbprint(public); thumbprint != expected {
+			t.Errorf("%s: expected thumbprint %v, got %v", algorithm, expected, thumbprint)
+		}
+	}
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	cache := crypto.NewMemoryReplayCache()
+
+	if cache.Seen("current", time.Now().Add(time.Minute)) {
+		t.Errorf("expected a new id not to be seen")
+	}
+	if !cache.Seen("current", time.Now().Add(time.Minute)) {
+		t.Errorf("expected a recorded id to be seen")
+	}
+
+	cache.Seen("expired", time.Now().Add(-time.Second))
+	if cache.Seen("expired", time.Now().Add(time.Minute)) {
+		t.Errorf("expected an expired id not to be seen, even before it is pruned")
+	}
+
+	cache.Seen("other", time.Now().Add(-time.Second))
+	if pruned := cache.Prune(); pruned != 1 {
+		t.Errorf("expected 1 pruned id, got %d", pruned)
+	}
+	if !cache.Seen("current", time.Now().Add(time.Minute)) {
+		t.Errorf("expected the ids that have not expired to be kept")
+	}
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrScopeNotGranted is returned when a delegation requests a scope or a role the subject token does not hold.
+var ErrScopeNotGranted = errors.New("requested scope exceeds the subject token")
+
+// ErrBoundSubjectToken is returned when the subject token of a delegation is bound to a DPoP key.
+var ErrBoundSubjectToken = errors.New("subject token is bound to a DPoP key")
+
+// Delegation describes the token requested by a client acting on behalf of the subject of a token.
+type Delegation struct {
+	// Actor is the id of the client the token is issued to.
+	Actor string
+	// Audience is the service the token is restricted to.
+	Audience string
+	// Scope is the requested scope, the scope of the subject token when empty.
+	Scope []string
+	// Roles are the requested roles, the roles of the subject token when empty.
+	Roles []string
+	// ExpiresAt caps the expiration date of the token, which never outlives the subject token.
+	ExpiresAt time.Time
+}
+
+// Downscope derives the claims of a delegated token from the claims of the subject token, following RFC 8693.
+// The delegated token keeps the subject, its session and its token version, is restricted to the audience
+// and carries an act claim naming the actor, on top of the actors of the subject token.
+// A subject token bound to a DPoP key is refused: the actor does not hold the key, and the delegated token
+// would otherwise be a bearer token usable without any proof.
+//
+// A subject token without scope grants every scope. The requested roles must be held by the subject token.
+// Returns ErrBoundSubjectToken if the subject token is bound to a DPoP key, or ErrScopeNotGranted
+// if the delegation requests more than the subject token grants.
+func Downscope(subject Claims, delegation Delegation) (Claims, error) {
+	if subject.BoundKey() != "" {
+		return Claims{}, ErrBoundSubjectToken
+	}
+
+	scope := subject.Scope
+	if len(delegation.Scope) > 0 {
+		granted := strings.Fields(subject.Scope)
+		for _, s := range delegation.Scope {
+			if len(granted) > 0 && !slices.Contains(granted, s) {
+				return Claims{}, ErrScopeNotGranted
+			}
+		}
+		scope = strings.Join(delegation.Scope, " ")
+	}
+
+	roles := subject.Roles
+	if len(delegation.Roles) > 0 {
+		for _, role := range delegation.Roles {
+			if !slices.Contains(subject.Roles, role) {
+				return Claims{}, ErrScopeNotGranted
+			}
+		}
+		roles = delegation.Roles
+	}
+
+	claims := NewClaims(subject.Subject, subject.Email, roles)
+	claims.Audience = jwt.ClaimStrings{delegation.Audience}
+	claims.Scope = scope
+	claims.ClientId = delegation.Actor
+	claims.TokenVersion = subject.TokenVersion
+	claims.SessionId = subject.SessionId
+	claims.Act = &Actor{Subject: delegation.Actor, Act: subject.Act}
+
+	claims.ExpiresAt = subject.ExpiresAt
+	if !delegation.ExpiresAt.IsZero() && (claims.ExpiresAt == nil || delegation.ExpiresAt.Before(claims.ExpiresAt.Time)) {
+		claims.ExpiresAt = jwt.NewNumericDate(delegation.ExpiresAt)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,99 @@
+package crypto_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func newSubjectClaims() crypto.Claims {
+	claims := crypto.NewClaims("uuid", "test@test.com", []string{"USER", "ADMIN"})
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	claims.SessionId = "sid"
+	claims.TokenVersion = 2
+
+	return claims
+}
+
+func TestDownscope(t *testing.T) {
+	subject := newSubjectClaims()
+
+	claims, err := crypto.Downscope(subject, crypto.Delegation{
+		Actor:    "billing",
+		Audience: "invoices",
+		Scope:    []string{"invoices:read"},
+		Roles:    []string{"USER"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if claims.Subject != "uuid" || claims.SessionId != "sid" || claims.TokenVersion != 2 {
+		t.Errorf("expected the subject, the session and the token version to be kept, got %v", claims)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "invoices" {
+		t.Errorf("expected audience invoices, got %v", claims.Audience)
+	}
+	if claims.Scope != "invoices:read" {
+		t.Errorf("expected scope invoices:read, got %v", claims.Scope)
+	}
+	if len(claims.Roles) != 1 || claims.Roles[0] != "USER" {
+		t.Errorf("expected roles [USER], got %v", claims.Roles)
+	}
+	if claims.Act == nil || claims.Act.Subject != "billing" || claims.ClientId != "billing" {
+		t.Errorf("expected actor billing, got %v", claims.Act)
+	}
+	if !claims.ExpiresAt.Equal(subject.ExpiresAt.Time) {
+		t.Errorf("expected expiration %v, got %v", subject.ExpiresAt, claims.ExpiresAt)
+	}
+}
+
+func TestDownscope_NestedActor(t *testing.T) {
+	subject := newSubjectClaims()
+	subject.Scope = "invoices:read invoices:write"
+	subject.Act = &crypto.Actor{Subject: "gateway"}
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+	claims, err := crypto.Downscope(subject, crypto.Delegation{Actor: "billing", Audience: "invoices", ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if claims.Act.Subject != "billing" || claims.Act.Act == nil || claims.Act.Act.Subject != "gateway" {
+		t.Errorf("expected actors billing then gateway, got %v", claims.Act)
+	}
+	if claims.Scope != subject.Scope {
+		t.Errorf("expected scope %v, got %v", subject.Scope, claims.Scope)
+	}
+	if claims.ExpiresAt.Unix() != expiresAt.Unix() {
+		t.Errorf("expected expiration %v, got %v", expiresAt, claims.ExpiresAt)
+	}
+}
+
+func TestDownscope_NotGranted(t *testing.T) {
+	subject := newSubjectClaims()
+	subject.Scope = "invoices:read"
+
+	_, err := crypto.Downscope(subject, crypto.Delegation{Actor: "billing", Audience: "invoices", Scope: []string{"invoices:write"}})
+	if !errors.Is(err, crypto.ErrScopeNotGranted) {
+		t.Errorf("expected ErrScopeNotGranted, got %v", err)
+	}
+
+	_, err = crypto.Downscope(subject, crypto.Delegation{Actor: "billing", Audience: "invoices", Roles: []string{"SUPER_ADMIN"}})
+	if !errors.Is(err, crypto.ErrScopeNotGranted) {
+		t.Errorf("expected ErrScopeNotGranted, got %v", err)
+	}
+}
+
+func TestDownscope_BoundSubjectToken(t *testing.T) {
+	subject := newSubjectClaims()
+	subject.Cnf = &crypto.Confirmation{JKT: "thumbprint"}
+
+	_, err := crypto.Downscope(subject, crypto.Delegation{Actor: "billing", Audience: "invoices"})
+	if !errors.Is(err, crypto.ErrBoundSubjectToken) {
+		t.Errorf("expected ErrBoundSubjectToken, got %v", err)
+	}
+}
@@ -1,24 +1,33 @@
 package crypto
 
-import "github.com/golang-jwt/jwt/v5"
+// Token strategies, the formats in which JWTServiceInterface implementations issue access tokens.
+const (
+	STRATEGY_JWT    = "jwt"
+	STRATEGY_OPAQUE = "opaque"
+	STRATEGY_PASETO = "paseto"
+)
 
 // JWTServiceInterface defines the methods required for managing JWT tokens.
 // It includes methods for generating and verifying JWT tokens.
+// Implementations sign either with a shared secret (HS256) or with an asymmetric private key (RS256, ES256, EdDSA),
+// or issue opaque tokens referencing claims kept by the service (OpaqueTokenService), or PASETO v4 tokens (PasetoService).
 type JWTServiceInterface interface {
-	// Generate creates a JWT token for a given user.
-	// Uses an environment variable "JWT_SECRET" as the secret key.
-	// The token expires in 20 days from the time of generation.
+	// Generate creates a JWT token carrying the given claims.
+	// The token id, the issue date, the issuer and the audience are set by the service.
+	// The token is short-lived, it expires at the date set in the claims or 15 minutes after its generation,
+	// clients are expected to renew it with a refresh token.
 	//
 	// Parameters:
-	// - user: the identifier for the user for whom the token is being generated.
+	// - claims: the claims of the token, built with NewClaims from the uuid, the email and the roles of the user.
 	//
 	// Returns:
 	// - The signed JWT token.
 	// - An error if any occurs during the token generation.
-	Generate(user string) (string, error)
+	Generate(claims Claims) (string, error)
 
 	// Verify checks if a given JWT token is valid.
-	// Uses an environment variable "JWT_SECRET" as the secret key.
+	// The issuer, the audience and the expiration date are enforced, with a leeway for clock skew.
+	// Tokens whose id (jti) has been revoked are rejected.
 	//
 	// Parameters:
 	// - tokenString: the JWT token to be verified.
@@ -27,7 +36,73 @@ type JWTServiceInterface interface {
 	// - A boolean indicating if the token is valid.
 	// - The token claims if the token is valid.
 	// - An error if any occurs during the token verification.
-	Verify(tokenString string) (bool, jwt.RegisteredClaims, error)
+	Verify(tokenString string) (bool, Claims, error)
+
+	// VerifyAnyAudience checks if a given JWT token is valid like Verify, without requiring the audience
+	// of the service. It serves the introspection of the tokens issued for other services,
+	// such as the tokens of a token exchange, and must not be used to authenticate a request.
+	//
+	// Parameters:
+	// - tokenString: the JWT token to be verified.
+	//
+	// Returns:
+	// - A boolean indicating if the token is valid.
+	// - The token claims if the token is valid.
+	// - An error if any occurs during the token verification.
+	VerifyAnyAudience(tokenString string) (bool, Claims, error)
+
+	// VerifyExpired checks if a given JWT token is valid like Verify, but also accepts it once expired.
+	// It serves the logout, which must end the session of an expired token, and must not be used
+	// to authenticate a request.
+	//
+	// Parameters:
+	// - tokenString: the JWT token to be verified.
+	//
+	// Returns:
+	// - A boolean indicating if the token is valid.
+	// - The token claims if the token is valid.
+	// - An error if any occurs during the token verification.
+	VerifyExpired(tokenString string) (bool, Claims, error)
+}
+
+// PublicKeyProviderInterface defines the methods required to publish the public keys verifying tokens.
+// It is implemented by the JWT services signing with a keyring.
+type PublicKeyProviderInterface interface {
+	// PublicKeys returns every key whose tokens can still be verified, the active signing key first.
+	PublicKeys() []SigningKey
+}
+
+// TokenDenylistInterface defines the methods required to look up revoked tokens.
+type TokenDenylistInterface interface {
+	// IsRevoked checks if the token with the given id (jti) has been revoked.
+	//
+	// Parameters:
+	// - jti: the unique id of the token.
+	//
+	// Returns:
+	// - A boolean indicating if the token has been revoked.
+	// - An error if any occurs during the lookup.
+	IsRevoked(jti string) (bool, error)
+}
+
+// RefreshTokenServiceInterface defines the methods required for managing opaque refresh tokens.
+type RefreshTokenServiceInterface interface {
+	// Generate creates a new random refresh token.
+	//
+	// Returns:
+	// - The refresh token to send to the client.
+	// - The hash of the refresh token, the only value that must be persisted.
+	// - An error if any occurs during the token generation.
+	Generate() (string, string, error)
+
+	// Hash computes the hash under which a refresh token is persisted.
+	//
+	// Parameters:
+	// - token: the refresh token sent by the client.
+	//
+	// Returns:
+	// - The hash of the refresh token.
+	Hash(token string) string
 }
 
 // PasswordServiceInterface defines the methods required for managing passwords.
@@ -37,13 +112,19 @@ type PasswordServiceInterface interface {
 	// Returns the generated password and an error if any occurs.
 	Generate() (string, error)
 
-	// Hash generates a secure hash for a given password using the Argon2 function.
-	// Uses an environment variable "ENCRYPT_SALT" as the salt.
-	// Returns the base64 encoded hash and an error if any occurs.
+	// Hash generates a secure hash for a given password using the Argon2id function, a random salt
+	// and the current pepper, if any.
+	// Returns the hash in the PHC string format and an error if any occurs.
 	Hash(password string) (string, error)
 
-	// Verify checks if a given password matches a hash using the Argon2 function.
-	// Uses an environment variable "ENCRYPT_SALT" as the salt.
+	// Verify checks if a given password matches a hash using the Argon2id function.
+	// The parameters, the pepper id and the salt are read from the PHC formatted hash, legacy hashes
+	// use the environment variable "ENCRYPT_SALT" as the salt.
+	// The bcrypt, PBKDF2-SHA256 and scrypt hashes imported from other systems are verified as well.
 	// Returns a boolean indicating if the password is valid and an error if any occurs.
 	Verify(password string, hash string) (bool, error)
+
+	// NeedsRehash checks if a hash is outdated and must be replaced by a new hash of the password
+	// after a successful verification.
+	NeedsRehash(hash string) bool
 }
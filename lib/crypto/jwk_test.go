@@ -0,0 +1,97 @@
+package crypto_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func TestThumbprint_RFC7638Example(t *testing.T) {
+	jwk := crypto.JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Kid: "ignored",
+		Alg: "RS256",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if thumbprint != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("expected thumbprint 'NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs', got %v", thumbprint)
+	}
+}
+
+func TestPublicJWK_ECDSA(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwk, err := crypto.PublicJWK(&key.PublicKey)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		t.Errorf("expected EC P-256 key, got %v %v", jwk.Kty, jwk.Crv)
+	}
+	if len(jwk.X) != 43 || len(jwk.Y) != 43 {
+		t.Errorf("expected 32 bytes coordinates, got %v and %v", jwk.X, jwk.Y)
+	}
+}
+
+func TestPublicJWK_Ed25519(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	jwk, err := crypto.PublicJWK(public)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+		t.Errorf("expected OKP Ed25519 key, got %v %v", jwk.Kty, jwk.Crv)
+	}
+}
+
+func TestPublicJWK_UnsupportedCurve(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	_, err := crypto.PublicJWK(&key.PublicKey)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestNewJWKSet(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	first, _ := crypto.NewSigningKey(crypto.ALG_ES256, ecKey, "first")
+	second, _ := crypto.NewSigningKey(crypto.ALG_EDDSA, edKey, "second")
+
+	set, err := crypto.NewJWKSet([]crypto.SigningKey{first, second})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+	if set.Keys[0].Kid != "first" || set.Keys[0].Alg != "ES256" || set.Keys[0].Use != "sig" {
+		t.Errorf("expected first ES256 signature key, got %v", set.Keys[0])
+	}
+	if set.Keys[1].Kid != "second" || set.Keys[1].Alg != "EdDSA" || set.Keys[1].Use != "sig" {
+		t.Errorf("expected second EdDSA signature key, got %v", set.Keys[1])
+	}
+}
+
+func TestNewJWKSet_Empty(t *testing.T) {
+	set, err := crypto.NewJWKSet(nil)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if set.Keys == nil || len(set.Keys) != 0 {
+		t.Errorf("expected empty key list, got %v", set.Keys)
+	}
+}
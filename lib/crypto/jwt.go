@@ -1,6 +1,8 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -8,7 +10,22 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type JWTService struct{}
+// AccessTokenLifetime is the default duration an access token stays valid once issued.
+const AccessTokenLifetime = 15 * time.Minute
+
+// ErrTokenRevoked is returned by Verify when the id of the token is in the denylist.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// JWTService signs tokens with the single HS256 secret read from the environment on every call.
+// Changing the secret invalidates every issued token, KeyringJWTService should be preferred
+// as it supports key rotation.
+type JWTService struct {
+	// Config sets the issuer, the audience and the leeway of the tokens.
+	Config TokenConfig
+	// Denylist is consulted by Verify to reject revoked tokens.
+	// Revocation is not checked when it is nil.
+	Denylist TokenDenylistInterface
+}
 
 // NewJWTService creates a new instance of JWTService.
 // Returns a pointer to the newly created JWTService.
@@ -16,22 +33,20 @@ func NewJWTService() *JWTService {
 	return &JWTService{}
 }
 
-// Generate creates a JWT token for a given user.
+// Generate creates a JWT token carrying the given claims.
 // Uses an environment variable "JWT_SECRET" as the secret key.
-// The token expires in 20 days from the time of generation.
+// The token expires at the date set in the claims, 15 minutes after its generation by default,
+// and carries a unique id (jti).
 // Returns the signed JWT token and an error if any occurs.
-func (j *JWTService) Generate(user string) (string, error) {
+func (j *JWTService) Generate(claims Claims) (string, error) {
 	key := os.Getenv("JWT_SECRET")
 	if key == "" {
 		return "", errors.New("env variable JWT_SECRET is not set")
 	}
 
-	expiresAt := time.Now().Unix() + 3600*24*20
-
-	claims := jwt.RegisteredClaims{
-		Issuer:    user,
-		ExpiresAt: jwt.NewNumericDate(time.Unix(expiresAt, 0)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	claims, err := j.Config.prepareClaims(claims)
+	if err != nil {
+		return "", err
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -45,23 +60,78 @@ func (j *JWTService) Generate(user string) (string, error) {
 
 // Verify checks if a given JWT token is valid.
 // Uses an environment variable "JWT_SECRET" as the secret key.
+// The issuer and the audience of the configuration are enforced, and a token whose id is in the denylist
+// is rejected with ErrTokenRevoked.
 // Returns a boolean indicating if the token is valid, the token claims, and an error if any occurs.
-func (j *JWTService) Verify(tokenString string) (bool, jwt.RegisteredClaims, error) {
+func (j *JWTService) Verify(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config)
+}
+
+// VerifyAnyAudience checks if a given JWT token is valid like Verify, whatever its audience.
+func (j *JWTService) VerifyAnyAudience(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config.anyAudience())
+}
+
+// VerifyExpired checks if a given JWT token is valid like Verify, even once expired.
+func (j *JWTService) VerifyExpired(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config.expired())
+}
+
+// verify checks a JWT token against the given configuration.
+func (j *JWTService) verify(tokenString string, config TokenConfig) (bool, Claims, error) {
 	key := os.Getenv("JWT_SECRET")
 	if key == "" {
-		return false, jwt.RegisteredClaims{}, errors.New("env variable JWT_SECRET is not set")
+		return false, Claims{}, errors.New("env variable JWT_SECRET is not set")
 	}
 
-	var claims jwt.RegisteredClaims
+	var claims Claims
 
-	token, err := jwt.NewParser().ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+	// The claims are checked once the signature is verified
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{ALG_HS256}), jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(key), nil
 	})
 	if err != nil {
-		return false, jwt.RegisteredClaims{}, err
+		return false, Claims{}, err
+	}
+
+	if err := config.validate(claims); err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := checkDenylist(j.Denylist, claims.ID); err != nil {
+		return false, Claims{}, err
 	}
 
 	valid := token.Valid
 
 	return valid, claims, nil
 }
+
+// newTokenId generates a random token id to use as jti claim.
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// checkDenylist returns ErrTokenRevoked if the token id is in the denylist.
+// Tokens issued without an id cannot be revoked and are always accepted.
+func checkDenylist(denylist TokenDenylistInterface, jti string) error {
+	if denylist == nil || jti == "" {
+		return nil
+	}
+
+	revoked, err := denylist.IsRevoked(jti)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
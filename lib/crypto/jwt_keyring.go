@@ -0,0 +1,121 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyringJWTService signs tokens with the active key of a keyring.
+// Tokens carry the key id in their "kid" header and are verified with any non-retired key of the keyring,
+// so rotating the signing key does not invalidate the tokens already issued.
+type KeyringJWTService struct {
+	Keyring *Keyring
+	// Config sets the issuer, the audience and the leeway of the tokens.
+	Config TokenConfig
+	// Denylist is consulted by Verify to reject revoked tokens.
+	// Revocation is not checked when it is nil.
+	Denylist TokenDenylistInterface
+}
+
+// NewKeyringJWTService creates a new instance of KeyringJWTService using the given keyring.
+// Returns a pointer to the newly created KeyringJWTService.
+func NewKeyringJWTService(keyring *Keyring) *KeyringJWTService {
+	return &KeyringJWTService{Keyring: keyring}
+}
+
+// Generate creates a JWT token carrying the given claims, signed with the active key of the keyring.
+// The token expires at the date set in the claims, 15 minutes after its generation by default,
+// and carries a unique id (jti).
+// Returns the signed JWT token and an error if any occurs.
+func (j *KeyringJWTService) Generate(claims Claims) (string, error) {
+	key, err := j.Keyring.Active()
+	if err != nil {
+		return "", err
+	}
+
+	claims, err = j.Config.prepareClaims(claims)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+
+	signedToken, err := token.SignedString(key.signingMaterial())
+	if err != nil {
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// Verify checks if a given JWT token is valid, using the key of the keyring matching its "kid" header.
+// The token must be signed with the algorithm of that key, and the key must not be retired.
+// The issuer and the audience of the configuration are enforced, and a token whose id is in the denylist
+// is rejected with ErrTokenRevoked.
+// Returns a boolean indicating if the token is valid, the token claims, and an error if any occurs.
+func (j *KeyringJWTService) Verify(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config)
+}
+
+// VerifyAnyAudience checks if a given JWT token is valid like Verify, whatever its audience.
+func (j *KeyringJWTService) VerifyAnyAudience(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config.anyAudience())
+}
+
+// VerifyExpired checks if a given JWT token is valid like Verify, even once expired.
+func (j *KeyringJWTService) VerifyExpired(tokenString string) (bool, Claims, error) {
+	return j.verify(tokenString, j.Config.expired())
+}
+
+// verify checks a JWT token against the given configuration.
+func (j *KeyringJWTService) verify(tokenString string, config TokenConfig) (bool, Claims, error) {
+	var claims Claims
+
+	// The claims are checked once the signature is verified
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{ALG_HS256, ALG_RS256, ALG_ES256, ALG_EDDSA}), jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid header")
+		}
+
+		key, ok := j.Keyring.Find(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %s", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("signing key %s does not use %s", kid, token.Method.Alg())
+		}
+
+		return key.verificationMaterial(), nil
+	})
+	if err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := config.validate(claims); err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := checkDenylist(j.Denylist, claims.ID); err != nil {
+		return false, Claims{}, err
+	}
+
+	return token.Valid, claims, nil
+}
+
+// PublicKeys returns the public keys of the keyring that can still verify tokens, the active key first.
+// Shared HS256 secrets are never returned.
+func (j *KeyringJWTService) PublicKeys() []SigningKey {
+	keys := []SigningKey{}
+	for _, key := range j.Keyring.VerificationKeys() {
+		if !key.Symmetric() {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
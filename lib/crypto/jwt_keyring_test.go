@@ -0,0 +1,267 @@
+package crypto_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+// writeKey writes a PKCS#8 PEM encoded private key in a temporary file and returns its path.
+func writeKey(t *testing.T, private any) string {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return path
+}
+
+func generateKeys(t *testing.T) map[string]any {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return map[string]any{
+		crypto.ALG_RS256: rsaKey,
+		crypto.ALG_ES256: ecKey,
+		crypto.ALG_EDDSA: edKey,
+	}
+}
+
+// newKeyringService creates a keyring JWT service whose active key is the given key.
+func newKeyringService(t *testing.T, key crypto.SigningKey) *crypto.KeyringJWTService {
+	keyring := crypto.NewKeyring(key.Method.Alg(), time.Hour, 0)
+	if err := keyring.Init(key); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return crypto.NewKeyringJWTService(keyring)
+}
+
+func TestKeyringJWTService_GenerateAndVerify(t *testing.T) {
+	for algorithm, private := range generateKeys(t) {
+		key, err := crypto.LoadSigningKey(algorithm, writeKey(t, private), "")
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", algorithm, err)
+		}
+
+		jwtService := newKeyringService(t, key)
+		token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", algorithm, err)
+		}
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", algorithm, err)
+		}
+		if parsed.Header["kid"] != key.Kid {
+			t.Errorf("%s: expected kid %v, got %v", algorithm, key.Kid, parsed.Header["kid"])
+		}
+		if parsed.Header["alg"] != algorithm {
+			t.Errorf("%s: expected alg %v, got %v", algorithm, algorithm, parsed.Header["alg"])
+		}
+
+		valid, claims, err := jwtService.Verify(token)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", algorithm, err)
+		}
+		if !valid {
+			t.Errorf("%s: expected valid token, got invalid", algorithm)
+		}
+		if claims.Subject != testUuid {
+			t.Errorf("%s: expected subject %v, got %v", algorithm, testUuid, claims.Subject)
+		}
+	}
+}
+
+func TestLoadSigningKey_KeyMismatch(t *testing.T) {
+	keys := generateKeys(t)
+
+	_, err := crypto.LoadSigningKey(crypto.ALG_RS256, writeKey(t, keys[crypto.ALG_ES256]), "")
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestLoadSigningKey_CustomKid(t *testing.T) {
+	keys := generateKeys(t)
+
+	key, err := crypto.LoadSigningKey(crypto.ALG_EDDSA, writeKey(t, keys[crypto.ALG_EDDSA]), "my-key")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if key.Kid != "my-key" {
+		t.Errorf("expected kid 'my-key', got %v", key.Kid)
+	}
+}
+
+func TestKeyringJWTService_VerifyWrongKey(t *testing.T) {
+	keys := generateKeys(t)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	signingKey, _ := crypto.NewSigningKey(crypto.ALG_ES256, keys[crypto.ALG_ES256], "kid")
+	otherKey, _ := crypto.NewSigningKey(crypto.ALG_ES256, other, "kid")
+
+	token, err := newKeyringService(t, signingKey).Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	valid, _, err := newKeyringService(t, otherKey).Verify(token)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if valid {
+		t.Errorf("expected invalid token, got valid")
+	}
+}
+
+func TestKeyringJWTService_RejectsAlgorithmConfusion(t *testing.T) {
+	keys := generateKeys(t)
+	key, _ := crypto.NewSigningKey(crypto.ALG_RS256, keys[crypto.ALG_RS256], "")
+
+	// HS256 token using the kid of the RSA key, signed with the encoded public key as secret
+	publicKey, _ := x509.MarshalPKIXPublicKey(key.Public)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Issuer: "user@example.com"})
+	forged.Header["kid"] = key.Kid
+	token, err := forged.SignedString(publicKey)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	valid, _, err := newKeyringService(t, key).Verify(token)
+	if err == nil || !strings.Contains(err.Error(), "does not use HS256") {
+		t.Errorf("expected algorithm mismatch error, got %v", err)
+	}
+	if valid {
+		t.Errorf("expected invalid token, got valid")
+	}
+}
+
+func TestKeyringJWTService_HS256(t *testing.T) {
+	key, err := crypto.NewSigningKey(crypto.ALG_HS256, []byte("test_secret"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	jwtService := newKeyringService(t, key)
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	valid, _, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid token, got invalid")
+	}
+	if len(jwtService.PublicKeys()) != 0 {
+		t.Errorf("expected no public key for a shared secret, got %v", jwtService.PublicKeys())
+	}
+}
+
+func TestKeyringJWTService_VerifyAfterRotation(t *testing.T) {
+	keys := generateKeys(t)
+	key, _ := crypto.NewSigningKey(crypto.ALG_ES256, keys[crypto.ALG_ES256], "")
+
+	jwtService := newKeyringService(t, key)
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	rotated, err := jwtService.Keyring.Rotate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	valid, _, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected token signed by the previous key to be valid")
+	}
+
+	newToken, _ := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	if parsed.Header["kid"] != rotated.Kid {
+		t.Errorf("expected kid %v, got %v", rotated.Kid, parsed.Header["kid"])
+	}
+
+	if len(jwtService.PublicKeys()) != 2 {
+		t.Errorf("expected 2 public keys, got %d", len(jwtService.PublicKeys()))
+	}
+}
+
+func TestNewKeyringFromEnv(t *testing.T) {
+	keys := generateKeys(t)
+
+	os.Setenv("JWT_ALGORITHM", crypto.ALG_ES256)
+	os.Setenv("JWT_PRIVATE_KEY_PATH", writeKey(t, keys[crypto.ALG_ES256]))
+	os.Setenv("JWT_KEY_ID", "initial")
+	defer os.Unsetenv("JWT_ALGORITHM")
+	defer os.Unsetenv("JWT_PRIVATE_KEY_PATH")
+	defer os.Unsetenv("JWT_KEY_ID")
+
+	keyring, initial, err := crypto.NewKeyringFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if keyring.Algorithm != crypto.ALG_ES256 {
+		t.Errorf("expected algorithm ES256, got %v", keyring.Algorithm)
+	}
+	if initial.Kid != "initial" {
+		t.Errorf("expected kid 'initial', got %v", initial.Kid)
+	}
+}
+
+func TestNewKeyringFromEnv_NoSecret(t *testing.T) {
+	os.Unsetenv("JWT_ALGORITHM")
+	os.Unsetenv("JWT_SECRET")
+
+	_, _, err := crypto.NewKeyringFromEnv()
+	if err == nil || err.Error() != "env variable JWT_SECRET is not set" {
+		t.Errorf("expected error 'env variable JWT_SECRET is not set', got %v", err)
+	}
+}
+
+func TestNewKeyringFromEnv_UnsupportedAlgorithm(t *testing.T) {
+	os.Setenv("JWT_ALGORITHM", "none")
+	defer os.Unsetenv("JWT_ALGORITHM")
+
+	_, _, err := crypto.NewKeyringFromEnv()
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -1,12 +1,17 @@
 package crypto_test
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/hhertout/twirp_auth/lib/crypto"
 )
 
+const testUuid = "2f1d6b8e-7c0a-4f5e-9a52-3b8c1d0e4f6a"
+
 func TestNewJWTService(t *testing.T) {
 	jwtService := crypto.NewJWTService()
 	if jwtService == nil {
@@ -19,7 +24,7 @@ func TestGenerateToken(t *testing.T) {
 	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := crypto.NewJWTService()
-	token, err := jwtService.Generate("user@example.com")
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -33,7 +38,7 @@ func TestGenerate_NoSecret(t *testing.T) {
 	os.Unsetenv("JWT_SECRET")
 
 	jwtService := crypto.NewJWTService()
-	token, err := jwtService.Generate("user@example.com")
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
 
 	if err == nil {
 		t.Errorf("expected error, got nil")
@@ -51,7 +56,7 @@ func TestVerifyToken(t *testing.T) {
 	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := crypto.NewJWTService()
-	token, err := jwtService.Generate("user@example.com")
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -64,8 +69,14 @@ func TestVerifyToken(t *testing.T) {
 	if !valid {
 		t.Errorf("expected valid token, got invalid")
 	}
-	if claims.Issuer != "user@example.com" {
-		t.Errorf("expected issuer 'user@example.com', got %v", claims.Issuer)
+	if claims.Subject != testUuid {
+		t.Errorf("expected subject %v, got %v", testUuid, claims.Subject)
+	}
+	if claims.Email != "user@example.com" {
+		t.Errorf("expected email 'user@example.com', got %v", claims.Email)
+	}
+	if len(claims.Roles) != 1 || claims.Roles[0] != "USER" {
+		t.Errorf("expected roles [USER], got %v", claims.Roles)
 	}
 }
 
@@ -93,7 +104,7 @@ func TestVerify_NoSecret(t *testing.T) {
 	os.Unsetenv("JWT_SECRET")
 
 	jwtService := crypto.NewJWTService()
-	token, err := jwtService.Generate("user@example.com")
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
@@ -113,3 +124,281 @@ func TestVerify_NoSecret(t *testing.T) {
 		t.Errorf("expected empty issuer, got %v", claims.Issuer)
 	}
 }
+
+type denylistStub struct {
+	revoked map[string]bool
+}
+
+func (d denylistStub) IsRevoked(jti string) (bool, error) {
+	return d.revoked[jti], nil
+}
+
+func TestGenerateToken_UniqueId(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := crypto.NewJWTService()
+	first, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	second, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, firstClaims, _ := jwtService.Verify(first)
+	_, secondClaims, _ := jwtService.Verify(second)
+
+	if firstClaims.ID == "" {
+		t.Errorf("expected non-empty jti, got empty string")
+	}
+	if firstClaims.ID == secondClaims.ID {
+		t.Errorf("expected two different jti, got %v twice", firstClaims.ID)
+	}
+}
+
+func TestVerify_RevokedToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := crypto.NewJWTService()
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, claims, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	jwtService.Denylist = denylistStub{revoked: map[string]bool{claims.ID: true}}
+	valid, _, err := jwtService.Verify(token)
+
+	if err != crypto.ErrTokenRevoked {
+		t.Errorf("expected error %v, got %v", crypto.ErrTokenRevoked, err)
+	}
+	if valid {
+		t.Errorf("expected invalid token, got valid")
+	}
+}
+
+func TestVerify_NotRevokedToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := crypto.NewJWTService()
+	jwtService.Denylist = denylistStub{revoked: map[string]bool{"other": true}}
+
+	token, err := jwtService.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	valid, _, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid token, got invalid")
+	}
+}
+
+func TestVerify_IssuerAndAudience(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	issuer := crypto.NewJWTService()
+	issuer.Config = crypto.TokenConfig{Issuer: "twirp_auth", Audience: "api"}
+
+	token, err := issuer.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	valid, claims, err := issuer.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid token, got invalid")
+	}
+	if claims.Issuer != "twirp_auth" {
+		t.Errorf("expected issuer 'twirp_auth', got %v", claims.Issuer)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "api" {
+		t.Errorf("expected audience [api], got %v", claims.Audience)
+	}
+
+	otherAudience := crypto.NewJWTService()
+	otherAudience.Config = crypto.TokenConfig{Issuer: "twirp_auth", Audience: "other"}
+	if valid, _, err := otherAudience.Verify(token); err == nil || valid {
+		t.Errorf("expected token for another audience to be rejected")
+	}
+
+	otherIssuer := crypto.NewJWTService()
+	otherIssuer.Config = crypto.TokenConfig{Issuer: "other", Audience: "api"}
+	if valid, _, err := otherIssuer.Verify(token); err == nil || valid {
+		t.Errorf("expected token from another issuer to be rejected")
+	}
+}
+
+func TestVerifyAnyAudience(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	service := crypto.NewJWTService()
+	service.Config = crypto.TokenConfig{Issuer: "twirp_auth", Audience: "api"}
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", []string{"USER"})
+	claims.Audience = jwt.ClaimStrings{"downstream"}
+	token, _ := service.Generate(claims)
+
+	if valid, _, err := service.Verify(token); err == nil || valid {
+		t.Errorf("expected token for another audience to be rejected by Verify")
+	}
+
+	valid, claims, err := service.VerifyAnyAudience(token)
+	if err != nil || !valid {
+		t.Errorf("expected token for another audience to be valid, got %v", err)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "downstream" {
+		t.Errorf("expected audience [downstream], got %v", claims.Audience)
+	}
+
+	otherIssuer := crypto.NewJWTService()
+	otherIssuer.Config = crypto.TokenConfig{Issuer: "other", Audience: "api"}
+	if valid, _, err := otherIssuer.VerifyAnyAudience(token); err == nil || valid {
+		t.Errorf("expected token from another issuer to be rejected")
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	service := crypto.NewJWTService()
+	service.Config = crypto.TokenConfig{Issuer: "twirp_auth", Audience: "api"}
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", []string{"USER"})
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	token, _ := service.Generate(claims)
+
+	if valid, _, err := service.Verify(token); !errors.Is(err, jwt.ErrTokenExpired) || valid {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+
+	valid, claims, err := service.VerifyExpired(token)
+	if err != nil || !valid {
+		t.Errorf("expected the expired token to be valid, got %v", err)
+	}
+	if claims.Subject != testUuid {
+		t.Errorf("expected subject %s, got %s", testUuid, claims.Subject)
+	}
+
+	otherAudience := crypto.NewJWTService()
+	otherAudience.Config = crypto.TokenConfig{Issuer: "twirp_auth", Audience: "other"}
+	if valid, _, err := otherAudience.VerifyExpired(token); !errors.Is(err, jwt.ErrTokenInvalidAudience) || valid {
+		t.Errorf("expected ErrTokenInvalidAudience, got %v", err)
+	}
+
+	os.Setenv("JWT_SECRET", "other_secret")
+	if valid, _, err := service.VerifyExpired(token); !errors.Is(err, jwt.ErrTokenSignatureInvalid) || valid {
+		t.Errorf("expected ErrTokenSignatureInvalid, got %v", err)
+	}
+}
+
+func TestVerify_Leeway(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	// Token issued by a server whose clock is ahead
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(10 * time.Second))
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+
+	strict := crypto.NewJWTService()
+	if valid, _, err := strict.Verify(token); err == nil || valid {
+		t.Errorf("expected token issued in the future to be rejected without leeway")
+	}
+
+	tolerant := crypto.NewJWTService()
+	tolerant.Config = crypto.TokenConfig{Leeway: 30 * time.Second}
+	if valid, _, err := tolerant.Verify(token); err != nil || !valid {
+		t.Errorf("expected token to be accepted within the leeway, got %v", err)
+	}
+}
+
+func TestGenerateToken_ExpiresAt(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	expiresAt := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	jwtService := crypto.NewJWTService()
+	token, err := jwtService.Generate(claims)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, verified, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !verified.ExpiresAt.Time.Equal(expiresAt) {
+		t.Errorf("expected expiration %v, got %v", expiresAt, verified.ExpiresAt.Time)
+	}
+}
+
+func TestVerify_TokenVersion(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.TokenVersion = 3
+
+	jwtService := crypto.NewJWTService()
+	token, _ := jwtService.Generate(claims)
+
+	_, verified, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := verified.CheckVersion(3); err != nil {
+		t.Errorf("expected current version to be accepted, got %v", err)
+	}
+	if err := verified.CheckVersion(4); !errors.Is(err, crypto.ErrTokenVersionStale) {
+		t.Errorf("expected ErrTokenVersionStale, got %v", err)
+	}
+}
+
+func TestVerify_PasswordChangeScope(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := crypto.NewJWTService()
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	token, _ := jwtService.Generate(claims)
+	_, verified, err := jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if verified.PasswordChangeRequired() {
+		t.Errorf("expected an unrestricted token")
+	}
+
+	claims.Scope = crypto.SCOPE_PASSWORD_CHANGE
+	token, _ = jwtService.Generate(claims)
+	_, verified, err = jwtService.Verify(token)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !verified.PasswordChangeRequired() {
+		t.Errorf("expected a token restricted to the change of the password, got scope %q", verified.Scope)
+	}
+}
@@ -0,0 +1,420 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+// keyringReloadDelay is the minimum delay between two reloads triggered by an unknown key id.
+const keyringReloadDelay = 30 * time.Second
+
+// ErrRotationInProgress is returned by Keyring.Rotate when another instance holds the rotation lock.
+var ErrRotationInProgress = errors.New("signing keys are being rotated by another instance")
+
+// KeyRecord is the persisted form of a keyring key.
+// Material holds the key encoded by MarshalSigningKey, sealed with the secret of the keyring.
+type KeyRecord struct {
+	Kid       string
+	Algorithm string
+	Material  []byte
+	CreatedAt time.Time
+	// ActivatesAt is the date the key starts signing tokens, it is only published before.
+	ActivatesAt   time.Time
+	DeactivatedAt *time.Time
+}
+
+// KeyStoreInterface defines the methods required to share the keys of a keyring between instances.
+type KeyStoreInterface interface {
+	// FindAll returns every persisted key, the most recently created first.
+	FindAll() ([]KeyRecord, error)
+
+	// Create persists a new key and deactivates the previously active ones when the new key activates.
+	Create(record KeyRecord) error
+
+	// DeleteDeactivatedBefore removes the keys deactivated before the given time.
+	// Returns the number of removed keys.
+	DeleteDeactivatedBefore(before time.Time) (int, error)
+
+	// WithRotationLock runs fn while holding a lock shared by every instance, so that a single instance
+	// rotates the keys at a time.
+	// Returns false without running fn when another instance holds the lock.
+	WithRotationLock(fn func() error) (bool, error)
+}
+
+// KeyringEntry is a key of the keyring along with its lifecycle dates.
+// A key is published from its creation, signs tokens from its activation until it is deactivated,
+// then only verifies them until the grace period is over.
+type KeyringEntry struct {
+	Key           SigningKey
+	CreatedAt     time.Time
+	ActivatesAt   time.Time
+	DeactivatedAt *time.Time
+}
+
+// Keyring holds the active signing key and the previous keys that can still verify tokens.
+// A key is retired once it has been deactivated for longer than the grace period, tokens signed
+// with it are refused from then on. The grace period must be longer than the lifetime of the tokens.
+// A rotated key is published during the publish delay before it signs tokens, so that the verifiers
+// caching the keys, and the other instances, know it by then.
+type Keyring struct {
+	Algorithm        string
+	Grace            time.Duration
+	RotationInterval time.Duration
+	PublishDelay     time.Duration
+
+	store      KeyStoreInterface
+	sealingKey []byte
+
+	mu         sync.RWMutex
+	entries    []KeyringEntry
+	lastReload time.Time
+}
+
+// NewKeyring creates an empty keyring generating keys for the given algorithm.
+// A RotationInterval of 0 disables the scheduled rotation.
+// Returns a pointer to the newly created Keyring.
+func NewKeyring(algorithm string, grace time.Duration, rotationInterval time.Duration) *Keyring {
+	return &Keyring{
+		Algorithm:        algorithm,
+		Grace:            grace,
+		RotationInterval: rotationInterval,
+	}
+}
+
+// UseStore persists the keys in the store, so that every instance shares the same keyring.
+// The key material is sealed with AES-GCM, using a key derived from the secret.
+func (k *Keyring) UseStore(store KeyStoreInterface, secret string) {
+	sum := sha256.Sum256([]byte(secret))
+	k.store = store
+	k.sealingKey = sum[:]
+}
+
+// Init loads the keys of the store. When the store is empty, or when the keyring has no store,
+// the initial key becomes the active key.
+func (k *Keyring) Init(initial SigningKey) error {
+	if k.store != nil {
+		if err := k.Reload(); err != nil {
+			return err
+		}
+		if len(k.entries) > 0 {
+			return nil
+		}
+	}
+
+	// No verifier knows the keys yet, the initial key signs right away
+	return k.activate(initial, time.Now())
+}
+
+// Active returns the key signing the new tokens: the most recent key that has been activated.
+func (k *Keyring) Active() (SigningKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	for _, entry := range k.entries {
+		if !entry.ActivatesAt.After(now) {
+			return entry.Key, nil
+		}
+	}
+
+	return SigningKey{}, errors.New("keyring has no active key")
+}
+
+// Find returns the non-retired key with the given key id.
+// An unknown key id may have been created by another instance, the store is reloaded to find it.
+func (k *Keyring) Find(kid string) (SigningKey, bool) {
+	if key, ok := k.find(kid); ok {
+		return key, true
+	}
+
+	k.mu.RLock()
+	reload := k.store != nil && time.Since(k.lastReload) > keyringReloadDelay
+	k.mu.RUnlock()
+
+	if !reload || k.Reload() != nil {
+		return SigningKey{}, false
+	}
+
+	return k.find(kid)
+}
+
+// VerificationKeys returns every non-retired key, the most recent first, the keys not activated yet included.
+func (k *Keyring) VerificationKeys() []SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := []SigningKey{}
+	for _, entry := range k.entries {
+		if !k.retired(entry) {
+			keys = append(keys, entry.Key)
+		}
+	}
+
+	return keys
+}
+
+// Rotate generates a new key, published right away and activated once the publish delay is over.
+// The previous active key signs tokens until then, and keeps verifying them during the grace period.
+// With a store, the rotation happens under the rotation lock, after a reload of the keys,
+// so that it does not race the rotation of another instance.
+// Returns ErrRotationInProgress when another instance holds the rotation lock.
+func (k *Keyring) Rotate() (SigningKey, error) {
+	if k.store == nil {
+		return k.rotate()
+	}
+
+	var key SigningKey
+	acquired, err := k.store.WithRotationLock(func() error {
+		if err := k.Reload(); err != nil {
+			return err
+		}
+
+		var err error
+		key, err = k.rotate()
+		return err
+	})
+	if err != nil {
+		return SigningKey{}, err
+	}
+	if !acquired {
+		return SigningKey{}, ErrRotationInProgress
+	}
+
+	return key, nil
+}
+
+// rotate generates a new key and activates it once the publish delay is over, without taking the rotation lock.
+func (k *Keyring) rotate() (SigningKey, error) {
+	key, err := GenerateSigningKey(k.Algorithm)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	if err := k.activate(key, time.Now().Add(k.PublishDelay)); err != nil {
+		return SigningKey{}, err
+	}
+
+	return key, nil
+}
+
+// RotateIfDue rotates the keyring when the most recent key is older than the rotation interval.
+// With a store, the rotation happens under the rotation lock, after a reload of the keys,
+// so that the instances sharing the store do not rotate the keys at the same time.
+// Returns a boolean indicating if a rotation happened.
+func (k *Keyring) RotateIfDue() (bool, error) {
+	if k.store == nil {
+		return k.rotateIfDue()
+	}
+
+	rotated := false
+	_, err := k.store.WithRotationLock(func() error {
+		// Another instance may have rotated the keys since the last reload
+		if err := k.Reload(); err != nil {
+			return err
+		}
+
+		var err error
+		rotated, err = k.rotateIfDue()
+		return err
+	})
+
+	return rotated, err
+}
+
+// rotateIfDue rotates the keyring when the most recent key of the loaded keys is older than the rotation interval.
+func (k *Keyring) rotateIfDue() (bool, error) {
+	k.mu.RLock()
+	due := k.RotationInterval > 0 && len(k.entries) > 0 && time.Since(k.entries[0].CreatedAt) >= k.RotationInterval
+	k.mu.RUnlock()
+
+	if !due {
+		return false, nil
+	}
+
+	_, err := k.rotate()
+	return err == nil, err
+}
+
+// CacheMaxAge returns how long the public keys may be cached by verifiers:
+// the given maximum, shortened so that caches expire when the next scheduled rotation happens.
+func (k *Keyring) CacheMaxAge(max time.Duration) time.Duration {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.RotationInterval <= 0 || len(k.entries) == 0 {
+		return max
+	}
+
+	untilRotation := time.Until(k.entries[0].CreatedAt.Add(k.RotationInterval))
+	if untilRotation < 0 {
+		return 0
+	}
+	if untilRotation < max {
+		return untilRotation
+	}
+
+	return max
+}
+
+// Reload replaces the keys of the keyring with the keys of the store.
+func (k *Keyring) Reload() error {
+	if k.store == nil {
+		return nil
+	}
+
+	records, err := k.store.FindAll()
+	if err != nil {
+		return err
+	}
+
+	entries := []KeyringEntry{}
+	for _, record := range records {
+		material, err := k.open(record.Material)
+		if err != nil {
+			return err
+		}
+
+		key, err := UnmarshalSigningKey(record.Algorithm, record.Kid, material)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, KeyringEntry{
+			Key:           key,
+			CreatedAt:     record.CreatedAt,
+			ActivatesAt:   record.ActivatesAt,
+			DeactivatedAt: record.DeactivatedAt,
+		})
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.entries = entries
+	k.lastReload = time.Now()
+
+	return nil
+}
+
+// Prune drops the retired keys from the keyring and from the store.
+// Returns the number of keys removed from the store.
+func (k *Keyring) Prune() (int, error) {
+	k.mu.Lock()
+	entries := []KeyringEntry{}
+	for _, entry := range k.entries {
+		if !k.retired(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	k.entries = entries
+	k.mu.Unlock()
+
+	if k.store == nil {
+		return 0, nil
+	}
+
+	return k.store.DeleteDeactivatedBefore(time.Now().Add(-k.Grace))
+}
+
+// activate adds the key to the keyring, persisting it when the keyring has a store.
+// The key becomes the active one at the given date, when the previously active keys are deactivated.
+func (k *Keyring) activate(key SigningKey, activatesAt time.Time) error {
+	now := time.Now()
+
+	if k.store != nil {
+		material, err := MarshalSigningKey(key)
+		if err != nil {
+			return err
+		}
+
+		sealed, err := k.seal(material)
+		if err != nil {
+			return err
+		}
+
+		err = k.store.Create(KeyRecord{
+			Kid:         key.Kid,
+			Algorithm:   key.Method.Alg(),
+			Material:    sealed,
+			CreatedAt:   now,
+			ActivatesAt: activatesAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := range k.entries {
+		if k.entries[i].DeactivatedAt == nil {
+			k.entries[i].DeactivatedAt = &activatesAt
+		}
+	}
+	k.entries = append([]KeyringEntry{{Key: key, CreatedAt: now, ActivatesAt: activatesAt}}, k.entries...)
+
+	return nil
+}
+
+// find looks up a non-retired key in memory.
+func (k *Keyring) find(kid string) (SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, entry := range k.entries {
+		if entry.Key.Kid == kid && !k.retired(entry) {
+			return entry.Key, true
+		}
+	}
+
+	return SigningKey{}, false
+}
+
+// retired reports whether the grace period of a deactivated key is over.
+func (k *Keyring) retired(entry KeyringEntry) bool {
+	return entry.DeactivatedAt != nil && time.Since(*entry.DeactivatedAt) > k.Grace
+}
+
+// seal encrypts key material before it is persisted.
+func (k *Keyring) seal(plaintext []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts key material sealed by seal.
+func (k *Keyring) open(sealed []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed key material is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func (k *Keyring) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.sealingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// NewKeyringFromEnv creates the keyring configured by the environment, along with its initial key.
+// The initial key only becomes active when the keyring has no persisted key yet.
+//
+// The algorithm is read from "JWT_ALGORITHM" and defaults to HS256:
+// - HS256 signs with the shared secret "JWT_SECRET".
+// - RS256, ES256 and EdDSA sign with the PEM encoded private key found at "JWT_PRIVATE_KEY_PATH".
+// "JWT_KEY_ID" sets the kid of the initial key.
+//
+// "JWT_KEY_GRACE_PERIOD" sets how long a previous key keeps verifying tokens (default 24h),
+// and "JWT_KEY_ROTATION_INTERVAL" how often a new key is generated (default 0, no scheduled rotation).
+func NewKeyringFromEnv() (*Keyring, SigningKey, error) {
+	grace, err := config.Duration("JWT_KEY_GRACE_PERIOD", 24*time.Hour)
+	if err != nil {
+		return nil, SigningKey{}, err
+	}
+
+	rotationInterval, err := config.Duration("JWT_KEY_ROTATION_INTERVAL", 0)
+	if err != nil {
+		return nil, SigningKey{}, err
+	}
+
+	if grace < AccessTokenLifetime {
+		return nil, SigningKey{}, errors.New("env variable JWT_KEY_GRACE_PERIOD must be longer than the access token lifetime")
+	}
+
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" {
+		algorithm = ALG_HS256
+	}
+
+	var initial SigningKey
+
+	switch algorithm {
+	case ALG_HS256:
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, SigningKey{}, errors.New("env variable JWT_SECRET is not set")
+		}
+
+		initial, err = NewSigningKey(algorithm, []byte(secret), os.Getenv("JWT_KEY_ID"))
+	case ALG_RS256, ALG_ES256, ALG_EDDSA:
+		path := os.Getenv("JWT_PRIVATE_KEY_PATH")
+		if path == "" {
+			return nil, SigningKey{}, errors.New("env variable JWT_PRIVATE_KEY_PATH is not set")
+		}
+
+		initial, err = LoadSigningKey(algorithm, path, os.Getenv("JWT_KEY_ID"))
+	default:
+		return nil, SigningKey{}, errors.New("unsupported JWT_ALGORITHM " + algorithm)
+	}
+	if err != nil {
+		return nil, SigningKey{}, err
+	}
+
+	return NewKeyring(algorithm, grace, rotationInterval), initial, nil
+}
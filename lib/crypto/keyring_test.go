@@ -0,0 +1,324 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+// memoryKeyStore keeps the records of a keyring in memory, most recent first.
+// Its rotation lock is held by another instance when locked is set.
+type memoryKeyStore struct {
+	records []crypto.KeyRecord
+	locked  bool
+}
+
+func (m *memoryKeyStore) FindAll() ([]crypto.KeyRecord, error) {
+	return m.records, nil
+}
+
+func (m *memoryKeyStore) Create(record crypto.KeyRecord) error {
+	for i := range m.records {
+		if m.records[i].DeactivatedAt == nil {
+			m.records[i].DeactivatedAt = &record.ActivatesAt
+		}
+	}
+	m.records = append([]crypto.KeyRecord{record}, m.records...)
+	return nil
+}
+
+func (m *memoryKeyStore) DeleteDeactivatedBefore(before time.Time) (int, error) {
+	kept := []crypto.KeyRecord{}
+	for _, record := range m.records {
+		if record.DeactivatedAt == nil || !record.DeactivatedAt.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := len(m.records) - len(kept)
+	m.records = kept
+	return deleted, nil
+}
+
+func (m *memoryKeyStore) WithRotationLock(fn func() error) (bool, error) {
+	if m.locked {
+		return false, nil
+	}
+
+	return true, fn()
+}
+
+func newKeyring(t *testing.T, grace time.Duration, rotationInterval time.Duration) (*crypto.Keyring, crypto.SigningKey) {
+	initial, err := crypto.GenerateSigningKey(crypto.ALG_EDDSA)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	keyring := crypto.NewKeyring(crypto.ALG_EDDSA, grace, rotationInterval)
+	if err := keyring.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return keyring, initial
+}
+
+func TestKeyring_Active(t *testing.T) {
+	keyring, initial := newKeyring(t, time.Hour, 0)
+
+	active, err := keyring.Active()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if active.Kid != initial.Kid {
+		t.Errorf("expected kid %v, got %v", initial.Kid, active.Kid)
+	}
+}
+
+func TestKeyring_RotateKeepsPreviousKeyDuringGrace(t *testing.T) {
+	keyring, initial := newKeyring(t, time.Hour, 0)
+
+	rotated, err := keyring.Rotate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	active, _ := keyring.Active()
+	if active.Kid != rotated.Kid {
+		t.Errorf("expected active kid %v, got %v", rotated.Kid, active.Kid)
+	}
+	if _, ok := keyring.Find(initial.Kid); !ok {
+		t.Errorf("expected previous key to be found during the grace period")
+	}
+	if len(keyring.VerificationKeys()) != 2 {
+		t.Errorf("expected 2 verification keys, got %d", len(keyring.VerificationKeys()))
+	}
+}
+
+func TestKeyring_RetiresPreviousKeyAfterGrace(t *testing.T) {
+	keyring, initial := newKeyring(t, 0, 0)
+
+	if _, err := keyring.Rotate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if _, ok := keyring.Find(initial.Kid); ok {
+		t.Errorf("expected previous key to be retired")
+	}
+	if len(keyring.VerificationKeys()) != 1 {
+		t.Errorf("expected 1 verification key, got %d", len(keyring.VerificationKeys()))
+	}
+}
+
+func TestKeyring_FindUnknownKid(t *testing.T) {
+	keyring, _ := newKeyring(t, time.Hour, 0)
+
+	if _, ok := keyring.Find("unknown"); ok {
+		t.Errorf("expected unknown kid not to be found")
+	}
+}
+
+func TestKeyring_RotateIfDue(t *testing.T) {
+	keyring, initial := newKeyring(t, time.Hour, time.Hour)
+
+	rotated, err := keyring.RotateIfDue()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if rotated {
+		t.Errorf("expected no rotation before the interval elapsed")
+	}
+
+	keyring.RotationInterval = time.Nanosecond
+	rotated, err = keyring.RotateIfDue()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !rotated {
+		t.Errorf("expected a rotation once the interval elapsed")
+	}
+
+	active, _ := keyring.Active()
+	if active.Kid == initial.Kid {
+		t.Errorf("expected a new active key")
+	}
+}
+
+func TestKeyring_RotatePublishesBeforeActivation(t *testing.T) {
+	keyring, initial := newKeyring(t, time.Hour, 0)
+	keyring.PublishDelay = time.Hour
+
+	rotated, err := keyring.Rotate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	active, _ := keyring.Active()
+	if active.Kid != initial.Kid {
+		t.Errorf("expected the previous key to sign until the new key activates, got kid %v", active.Kid)
+	}
+	if _, ok := keyring.Find(rotated.Kid); !ok {
+		t.Errorf("expected the new key to be published before its activation")
+	}
+	if len(keyring.VerificationKeys()) != 2 {
+		t.Errorf("expected 2 verification keys, got %d", len(keyring.VerificationKeys()))
+	}
+}
+
+func TestKeyring_RotateIfDue_Locked(t *testing.T) {
+	store := &memoryKeyStore{}
+	initial, _ := crypto.GenerateSigningKey(crypto.ALG_EDDSA)
+
+	keyring := crypto.NewKeyring(crypto.ALG_EDDSA, time.Hour, time.Nanosecond)
+	keyring.UseStore(store, "keyring_secret")
+	if err := keyring.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// Another instance is rotating the keys
+	store.locked = true
+	rotated, err := keyring.RotateIfDue()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if rotated || len(store.records) != 1 {
+		t.Errorf("expected no rotation while another instance holds the lock")
+	}
+
+	store.locked = false
+	rotated, err = keyring.RotateIfDue()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !rotated || len(store.records) != 2 {
+		t.Errorf("expected a rotation once the lock is free")
+	}
+
+	// The rotation of another instance is seen under the lock, the keys are not rotated twice
+	other := crypto.NewKeyring(crypto.ALG_EDDSA, time.Hour, time.Hour)
+	other.UseStore(store, "keyring_secret")
+	other.Init(initial)
+	if rotated, _ := other.RotateIfDue(); rotated {
+		t.Errorf("expected no rotation of the keys rotated by another instance")
+	}
+}
+
+func TestKeyring_Rotate_Locked(t *testing.T) {
+	store := &memoryKeyStore{}
+	initial, _ := crypto.GenerateSigningKey(crypto.ALG_EDDSA)
+
+	keyring := crypto.NewKeyring(crypto.ALG_EDDSA, time.Hour, 0)
+	keyring.UseStore(store, "keyring_secret")
+	if err := keyring.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The scheduled rotation of another instance is in progress
+	store.locked = true
+	if _, err := keyring.Rotate(); !errors.Is(err, crypto.ErrRotationInProgress) {
+		t.Errorf("expected ErrRotationInProgress, got %v", err)
+	}
+	if len(store.records) != 1 {
+		t.Errorf("expected no rotation while another instance holds the lock, got %d keys", len(store.records))
+	}
+
+	store.locked = false
+	if _, err := keyring.Rotate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(store.records) != 2 {
+		t.Errorf("expected a rotation once the lock is free, got %d keys", len(store.records))
+	}
+}
+
+func TestKeyring_CacheMaxAge(t *testing.T) {
+	keyring, _ := newKeyring(t, time.Hour, 0)
+	if keyring.CacheMaxAge(time.Hour) != time.Hour {
+		t.Errorf("expected max age 1h without scheduled rotation, got %v", keyring.CacheMaxAge(time.Hour))
+	}
+
+	keyring.RotationInterval = 10 * time.Minute
+	if maxAge := keyring.CacheMaxAge(time.Hour); maxAge > 10*time.Minute || maxAge <= 0 {
+		t.Errorf("expected max age shortened to the next rotation, got %v", maxAge)
+	}
+}
+
+func TestKeyring_Store(t *testing.T) {
+	store := &memoryKeyStore{}
+	initial, _ := crypto.GenerateSigningKey(crypto.ALG_ES256)
+
+	keyring := crypto.NewKeyring(crypto.ALG_ES256, time.Hour, 0)
+	keyring.UseStore(store, "keyring_secret")
+	if err := keyring.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rotated, err := keyring.Rotate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(store.records) != 2 {
+		t.Fatalf("expected 2 persisted keys, got %d", len(store.records))
+	}
+	material, _ := crypto.MarshalSigningKey(rotated)
+	if bytes.Contains(store.records[0].Material, material) {
+		t.Errorf("expected persisted key material to be sealed")
+	}
+
+	// Another instance sharing the store
+	other := crypto.NewKeyring(crypto.ALG_ES256, time.Hour, 0)
+	other.UseStore(store, "keyring_secret")
+	if err := other.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	active, _ := other.Active()
+	if active.Kid != rotated.Kid {
+		t.Errorf("expected active kid %v, got %v", rotated.Kid, active.Kid)
+	}
+	if _, ok := other.Find(initial.Kid); !ok {
+		t.Errorf("expected previous key to be loaded from the store")
+	}
+}
+
+func TestKeyring_StoreWrongSecret(t *testing.T) {
+	store := &memoryKeyStore{}
+	initial, _ := crypto.GenerateSigningKey(crypto.ALG_HS256)
+
+	keyring := crypto.NewKeyring(crypto.ALG_HS256, time.Hour, 0)
+	keyring.UseStore(store, "keyring_secret")
+	if err := keyring.Init(initial); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	other := crypto.NewKeyring(crypto.ALG_HS256, time.Hour, 0)
+	other.UseStore(store, "wrong_secret")
+	if err := other.Init(initial); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestKeyring_Prune(t *testing.T) {
+	store := &memoryKeyStore{}
+	initial, _ := crypto.GenerateSigningKey(crypto.ALG_EDDSA)
+
+	keyring := crypto.NewKeyring(crypto.ALG_EDDSA, 0, 0)
+	keyring.UseStore(store, "keyring_secret")
+	keyring.Init(initial)
+	keyring.Rotate()
+	time.Sleep(time.Millisecond)
+
+	deleted, err := keyring.Prune()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted key, got %d", deleted)
+	}
+	if len(store.records) != 1 {
+		t.Errorf("expected 1 persisted key, got %d", len(store.records))
+	}
+}
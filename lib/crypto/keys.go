@@ -0,0 +1,211 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported token signing algorithms.
+const (
+	ALG_HS256 = "HS256"
+	ALG_RS256 = "RS256"
+	ALG_ES256 = "ES256"
+	ALG_EDDSA = "EdDSA"
+)
+
+// SigningKey is a key used to sign and verify tokens.
+// It is identified by its key id (kid), written in the header of the tokens it signs.
+// HS256 keys only hold a shared Secret, the other algorithms hold a key pair.
+type SigningKey struct {
+	Kid     string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+	Secret  []byte
+}
+
+// NewSigningKey creates a signing key for the given algorithm from its key material.
+// The type of the material must match the algorithm: a []byte secret for HS256, a RSA private key for RS256,
+// an ECDSA P-256 private key for ES256 and an Ed25519 private key for EdDSA.
+// When kid is empty, the RFC 7638 thumbprint of the public key is used as key id,
+// or a hash of the secret for HS256.
+func NewSigningKey(algorithm string, material any, kid string) (SigningKey, error) {
+	if algorithm == ALG_HS256 {
+		secret, ok := material.([]byte)
+		if !ok || len(secret) == 0 {
+			return SigningKey{}, fmt.Errorf("algorithm %s requires a secret", algorithm)
+		}
+		if kid == "" {
+			sum := sha256.Sum256(secret)
+			kid = base64.RawURLEncoding.EncodeToString(sum[:12])
+		}
+		return SigningKey{Kid: kid, Method: jwt.SigningMethodHS256, Secret: secret}, nil
+	}
+
+	var method jwt.SigningMethod
+	var signer crypto.Signer
+
+	switch algorithm {
+	case ALG_RS256:
+		k, ok := material.(*rsa.PrivateKey)
+		if !ok {
+			return SigningKey{}, fmt.Errorf("algorithm %s requires a RSA private key", algorithm)
+		}
+		method, signer = jwt.SigningMethodRS256, k
+	case ALG_ES256:
+		k, ok := material.(*ecdsa.PrivateKey)
+		if !ok || k.Curve.Params().Name != "P-256" {
+			return SigningKey{}, fmt.Errorf("algorithm %s requires an ECDSA P-256 private key", algorithm)
+		}
+		method, signer = jwt.SigningMethodES256, k
+	case ALG_EDDSA:
+		k, ok := material.(ed25519.PrivateKey)
+		if !ok {
+			return SigningKey{}, fmt.Errorf("algorithm %s requires an Ed25519 private key", algorithm)
+		}
+		method, signer = jwt.SigningMethodEdDSA, k
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+
+	if kid == "" {
+		thumbprint, err := Thumbprint(signer.Public())
+		if err != nil {
+			return SigningKey{}, err
+		}
+		kid = thumbprint
+	}
+
+	return SigningKey{
+		Kid:     kid,
+		Method:  method,
+		Private: signer,
+		Public:  signer.Public(),
+	}, nil
+}
+
+// GenerateSigningKey creates a new random signing key for the given algorithm.
+func GenerateSigningKey(algorithm string) (SigningKey, error) {
+	var material any
+	var err error
+
+	switch algorithm {
+	case ALG_HS256:
+		secret := make([]byte, 32)
+		_, err = rand.Read(secret)
+		material = secret
+	case ALG_RS256:
+		material, err = rsa.GenerateKey(rand.Reader, 2048)
+	case ALG_ES256:
+		material, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ALG_EDDSA:
+		_, material, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	if algorithm == ALG_HS256 {
+		kid, err := newTokenId()
+		if err != nil {
+			return SigningKey{}, err
+		}
+		return NewSigningKey(algorithm, material, kid)
+	}
+
+	return NewSigningKey(algorithm, material, "")
+}
+
+// Symmetric reports whether the key is a shared secret that must never be published.
+func (k SigningKey) Symmetric() bool {
+	return k.Secret != nil
+}
+
+// signingMaterial returns the value expected by the signing method to sign a token.
+func (k SigningKey) signingMaterial() any {
+	if k.Symmetric() {
+		return k.Secret
+	}
+	return k.Private
+}
+
+// verificationMaterial returns the value expected by the signing method to verify a token.
+func (k SigningKey) verificationMaterial() any {
+	if k.Symmetric() {
+		return k.Secret
+	}
+	return k.Public
+}
+
+// MarshalSigningKey encodes the key material: the raw secret for HS256, PKCS#8 DER otherwise.
+func MarshalSigningKey(key SigningKey) ([]byte, error) {
+	if key.Symmetric() {
+		return key.Secret, nil
+	}
+
+	return x509.MarshalPKCS8PrivateKey(key.Private)
+}
+
+// UnmarshalSigningKey decodes key material encoded by MarshalSigningKey.
+func UnmarshalSigningKey(algorithm string, kid string, content []byte) (SigningKey, error) {
+	if algorithm == ALG_HS256 {
+		return NewSigningKey(algorithm, content, kid)
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(content)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return NewSigningKey(algorithm, private, kid)
+}
+
+// LoadSigningKey reads a PEM encoded private key from a file and creates a signing key for the given algorithm.
+// PKCS#8, PKCS#1 (RSA) and SEC 1 (EC) encodings are supported.
+func LoadSigningKey(algorithm string, path string, kid string) (SigningKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	private, err := ParsePrivateKeyPEM(content)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return NewSigningKey(algorithm, private, kid)
+}
+
+// ParsePrivateKeyPEM parses the first PEM block of the content as a private key.
+func ParsePrivateKeyPEM(content []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+}
@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// Token types whose lifetime is configurable.
+const (
+	TOKEN_ACCESS  = "access"
+	TOKEN_REFRESH = "refresh"
+)
+
+// defaultLifetimes are the lifetimes used when the environment does not set them.
+var defaultLifetimes = map[string]time.Duration{
+	TOKEN_ACCESS:  AccessTokenLifetime,
+	TOKEN_REFRESH: RefreshTokenLifetime,
+}
+
+// LifetimePolicy gives the lifetime of a token according to its type, the roles of the user
+// and whether the user asked to be remembered.
+type LifetimePolicy struct {
+	// Defaults are the lifetimes of each token type.
+	Defaults map[string]time.Duration
+	// RememberMe are the lifetimes of each token type when the user asked to be remembered.
+	// Token types missing from it use the default lifetime.
+	RememberMe map[string]time.Duration
+	// Roles caps the lifetimes of each token type for the users having a role.
+	Roles map[string]map[string]time.Duration
+}
+
+// NewLifetimePolicyFromEnv reads the lifetimes of every token type from the environment:
+// - "TOKEN_<TYPE>_TTL" sets the default lifetime, such as TOKEN_ACCESS_TTL=15m.
+// - "TOKEN_REMEMBER_ME_<TYPE>_TTL" sets the lifetime when the user asked to be remembered.
+// - "TOKEN_<ROLE>_<TYPE>_TTL" caps the lifetime for the users having the role, such as TOKEN_ADMIN_ACCESS_TTL=5m.
+func NewLifetimePolicyFromEnv(roles []string) (LifetimePolicy, error) {
+	policy := LifetimePolicy{
+		Defaults:   map[string]time.Duration{},
+		RememberMe: map[string]time.Duration{},
+		Roles:      map[string]map[string]time.Duration{},
+	}
+
+	for tokenType, fallback := range defaultLifetimes {
+		name := strings.ToUpper(tokenType)
+
+		lifetime, err := config.Duration("TOKEN_"+name+"_TTL", fallback)
+		if err != nil {
+			return LifetimePolicy{}, err
+		}
+		policy.Defaults[tokenType] = lifetime
+
+		rememberMe, err := config.Duration("TOKEN_REMEMBER_ME_"+name+"_TTL", 0)
+		if err != nil {
+			return LifetimePolicy{}, err
+		}
+		if rememberMe > 0 {
+			policy.RememberMe[tokenType] = rememberMe
+		}
+
+		for _, role := range roles {
+			roleLifetime, err := config.Duration("TOKEN_"+strings.ToUpper(role)+"_"+name+"_TTL", 0)
+			if err != nil {
+				return LifetimePolicy{}, err
+			}
+			if roleLifetime > 0 {
+				if policy.Roles[role] == nil {
+					policy.Roles[role] = map[string]time.Duration{}
+				}
+				policy.Roles[role][tokenType] = roleLifetime
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// Lifetime returns the lifetime of a token of the given type issued to a user having the roles.
+// The remember me lifetime replaces the default one, then the shortest cap of the roles applies,
+// so that privileged users keep short-lived tokens even when they asked to be remembered.
+func (p LifetimePolicy) Lifetime(tokenType string, roles []string, rememberMe bool) time.Duration {
+	lifetime, ok := p.Defaults[tokenType]
+	if !ok {
+		lifetime = defaultLifetimes[tokenType]
+	}
+
+	if rememberMe {
+		if l, ok := p.RememberMe[tokenType]; ok {
+			lifetime = l
+		}
+	}
+
+	for _, role := range roles {
+		if l, ok := p.Roles[role][tokenType]; ok && l < lifetime {
+			lifetime = l
+		}
+	}
+
+	return lifetime
+}
+
+// Longest returns the longest lifetime a token of the given type can be issued with,
+// whatever the roles of the user.
+func (p LifetimePolicy) Longest(tokenType string) time.Duration {
+	longest := p.Lifetime(tokenType, nil, false)
+	if l := p.Lifetime(tokenType, nil, true); l > longest {
+		longest = l
+	}
+
+	return longest
+}
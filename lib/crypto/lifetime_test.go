@@ -0,0 +1,71 @@
+package crypto_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func TestLifetimePolicy_Defaults(t *testing.T) {
+	policy, err := crypto.NewLifetimePolicyFromEnv([]string{"ADMIN", "USER"})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if l := policy.Lifetime(crypto.TOKEN_ACCESS, []string{"USER"}, false); l != crypto.AccessTokenLifetime {
+		t.Errorf("expected access lifetime %v, got %v", crypto.AccessTokenLifetime, l)
+	}
+	if l := policy.Lifetime(crypto.TOKEN_REFRESH, []string{"USER"}, true); l != crypto.RefreshTokenLifetime {
+		t.Errorf("expected refresh lifetime %v, got %v", crypto.RefreshTokenLifetime, l)
+	}
+}
+
+func TestLifetimePolicy_FromEnv(t *testing.T) {
+	os.Setenv("TOKEN_ACCESS_TTL", "10m")
+	os.Setenv("TOKEN_REFRESH_TTL", "24h")
+	os.Setenv("TOKEN_REMEMBER_ME_REFRESH_TTL", "720h")
+	os.Setenv("TOKEN_ADMIN_ACCESS_TTL", "5m")
+	os.Setenv("TOKEN_ADMIN_REFRESH_TTL", "8h")
+	defer os.Unsetenv("TOKEN_ACCESS_TTL")
+	defer os.Unsetenv("TOKEN_REFRESH_TTL")
+	defer os.Unsetenv("TOKEN_REMEMBER_ME_REFRESH_TTL")
+	defer os.Unsetenv("TOKEN_ADMIN_ACCESS_TTL")
+	defer os.Unsetenv("TOKEN_ADMIN_REFRESH_TTL")
+
+	policy, err := crypto.NewLifetimePolicyFromEnv([]string{"ADMIN", "USER"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cases := []struct {
+		tokenType  string
+		roles      []string
+		rememberMe bool
+		expected   time.Duration
+	}{
+		{crypto.TOKEN_ACCESS, []string{"USER"}, false, 10 * time.Minute},
+		{crypto.TOKEN_ACCESS, []string{"USER", "ADMIN"}, false, 5 * time.Minute},
+		{crypto.TOKEN_REFRESH, []string{"USER"}, false, 24 * time.Hour},
+		{crypto.TOKEN_REFRESH, []string{"USER"}, true, 720 * time.Hour},
+		{crypto.TOKEN_REFRESH, []string{"ADMIN"}, true, 8 * time.Hour},
+		{crypto.TOKEN_ACCESS, []string{"USER"}, true, 10 * time.Minute},
+	}
+
+	for _, c := range cases {
+		if l := policy.Lifetime(c.tokenType, c.roles, c.rememberMe); l != c.expected {
+			t.Errorf("%s %v remember=%v: expected %v, got %v", c.tokenType, c.roles, c.rememberMe, c.expected, l)
+		}
+	}
+}
+
+func TestLifetimePolicy_InvalidEnv(t *testing.T) {
+	os.Setenv("TOKEN_ADMIN_ACCESS_TTL", "five minutes")
+	defer os.Unsetenv("TOKEN_ADMIN_ACCESS_TTL")
+
+	_, err := crypto.NewLifetimePolicyFromEnv([]string{"ADMIN"})
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
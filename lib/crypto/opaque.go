@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrUnknownToken is returned by OpaqueTokenService.Verify when the token was never issued or has been pruned.
+var ErrUnknownToken = errors.New("unknown token")
+
+// ErrTokenExpired is returned by OpaqueTokenService.Verify when the token is expired.
+var ErrTokenExpired = errors.New("token is expired")
+
+// OpaqueTokenRecord is the persisted form of an opaque token.
+// Only the hash of the token is stored, along with its claims encoded as JSON.
+type OpaqueTokenRecord struct {
+	TokenHash string
+	Jti       string
+	Claims    []byte
+	ExpiresAt time.Time
+}
+
+// OpaqueTokenStoreInterface defines the methods required to persist the opaque tokens.
+type OpaqueTokenStoreInterface interface {
+	// Create persists a new token.
+	Create(record OpaqueTokenRecord) error
+
+	// FindOneByHash returns the token with the given hash.
+	// The returned record has an empty TokenHash when the token is unknown.
+	FindOneByHash(tokenHash string) (OpaqueTokenRecord, error)
+
+	// DeleteExpired removes the expired tokens.
+	// Returns the number of removed tokens.
+	DeleteExpired() (int, error)
+}
+
+// OpaqueTokenService issues random reference tokens instead of JWTs.
+// The claims never leave the service: they are stored along with the hash of the token,
+// and every verification looks the token up in the store.
+type OpaqueTokenService struct {
+	Store OpaqueTokenStoreInterface
+	// Config sets the issuer and the audience written in the stored claims.
+	Config TokenConfig
+	// Denylist is consulted by Verify to reject revoked tokens.
+	// Revocation is not checked when it is nil.
+	Denylist TokenDenylistInterface
+}
+
+// NewOpaqueTokenService creates a new instance of OpaqueTokenService storing the tokens in the given store.
+// Returns a pointer to the newly created OpaqueTokenService.
+func NewOpaqueTokenService(store OpaqueTokenStoreInterface) *OpaqueTokenService {
+	return &OpaqueTokenService{Store: store}
+}
+
+// Generate creates an opaque token made of 32 random bytes and stores its hash along with the claims.
+// The token expires at the date set in the claims, 15 minutes after its generation by default,
+// and carries a unique id (jti).
+// Returns the opaque token and an error if any occurs.
+func (o *OpaqueTokenService) Generate(claims Claims) (string, error) {
+	claims, err := o.Config.prepareClaims(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err = o.Store.Create(OpaqueTokenRecord{
+		TokenHash: o.Hash(token),
+		Jti:       claims.ID,
+		Claims:    encoded,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Verify looks the token up in the store and returns its claims.
+// Unknown and expired tokens are rejected, the issuer and the audience of the stored claims are enforced
+// as for the JWTs, and a token whose id is in the denylist is rejected with ErrTokenRevoked.
+// Returns a boolean indicating if the token is valid, the token claims, and an error if any occurs.
+func (o *OpaqueTokenService) Verify(tokenString string) (bool, Claims, error) {
+	return o.verify(tokenString, o.Config)
+}
+
+// VerifyAnyAudience looks the token up like Verify, whatever the audience of its claims.
+func (o *OpaqueTokenService) VerifyAnyAudience(tokenString string) (bool, Claims, error) {
+	return o.verify(tokenString, o.Config.anyAudience())
+}
+
+// VerifyExpired looks the token up like Verify, even once expired, as long as it has not been pruned.
+func (o *OpaqueTokenService) VerifyExpired(tokenString string) (bool, Claims, error) {
+	return o.verify(tokenString, o.Config.expired())
+}
+
+// verify looks the token up and checks its claims against the given configuration.
+func (o *OpaqueTokenService) verify(tokenString string, config TokenConfig) (bool, Claims, error) {
+	record, err := o.Store.FindOneByHash(o.Hash(tokenString))
+	if err != nil {
+		return false, Claims{}, err
+	}
+	if record.TokenHash == "" {
+		return false, Claims{}, ErrUnknownToken
+	}
+	if !config.allowExpired && time.Now().After(record.ExpiresAt) {
+		return false, Claims{}, ErrTokenExpired
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(record.Claims, &claims); err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := config.validate(claims); err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := checkDenylist(o.Denylist, claims.ID); err != nil {
+		return false, Claims{}, err
+	}
+
+	return true, claims, nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of an opaque token.
+func (o *OpaqueTokenService) Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PruneExpired removes the expired tokens from the store.
+// Returns the number of removed tokens.
+func (o *OpaqueTokenService) PruneExpired() (int, error) {
+	return o.Store.DeleteExpired()
+}
@@ -0,0 +1,154 @@
+package crypto_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+type memoryOpaqueStore struct {
+	records map[string]crypto.OpaqueTokenRecord
+}
+
+func (m *memoryOpaqueStore) Create(record crypto.OpaqueTokenRecord) error {
+	m.records[record.TokenHash] = record
+	return nil
+}
+
+func (m *memoryOpaqueStore) FindOneByHash(tokenHash string) (crypto.OpaqueTokenRecord, error) {
+	return m.records[tokenHash], nil
+}
+
+func (m *memoryOpaqueStore) DeleteExpired() (int, error) {
+	deleted := 0
+	for hash, record := range m.records {
+		if time.Now().After(record.ExpiresAt) {
+			delete(m.records, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newOpaqueService() (*crypto.OpaqueTokenService, *memoryOpaqueStore) {
+	store := &memoryOpaqueStore{records: map[string]crypto.OpaqueTokenRecord{}}
+	return crypto.NewOpaqueTokenService(store), store
+}
+
+func TestOpaque_GenerateAndVerify(t *testing.T) {
+	service, store := newOpaqueService()
+	service.Config = crypto.TokenConfig{Issuer: "auth", Audience: "api"}
+
+	token, err := service.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if strings.Count(token, ".") != 0 {
+		t.Errorf("expected an opaque token, got %v", token)
+	}
+	if _, ok := store.records[token]; ok {
+		t.Errorf("expected the token to be stored hashed")
+	}
+
+	valid, claims, err := service.Verify(token)
+	if err != nil || !valid {
+		t.Errorf("expected token to be valid, got %v", err)
+	}
+	if claims.Subject != testUuid || claims.Email != "user@example.com" || claims.Issuer != "auth" {
+		t.Errorf("expected stored claims, got %+v", claims)
+	}
+	if claims.ID == "" {
+		t.Errorf("expected a token id")
+	}
+}
+
+func TestOpaque_Verify_Unknown(t *testing.T) {
+	service, _ := newOpaqueService()
+
+	valid, _, err := service.Verify("unknown")
+	if !errors.Is(err, crypto.ErrUnknownToken) || valid {
+		t.Errorf("expected ErrUnknownToken, got %v", err)
+	}
+}
+
+func TestOpaque_Verify_Expired(t *testing.T) {
+	service, _ := newOpaqueService()
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	token, _ := service.Generate(claims)
+
+	valid, _, err := service.Verify(token)
+	if !errors.Is(err, crypto.ErrTokenExpired) || valid {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+
+	valid, _, err = service.VerifyExpired(token)
+	if err != nil || !valid {
+		t.Errorf("expected the expired token to be valid until it is pruned, got %v", err)
+	}
+
+	pruned, _ := service.PruneExpired()
+	if pruned != 1 {
+		t.Errorf("expected 1 pruned token, got %d", pruned)
+	}
+}
+
+func TestOpaque_Verify_Revoked(t *testing.T) {
+	service, _ := newOpaqueService()
+	token, _ := service.Generate(crypto.NewClaims(testUuid, "user@example.com", nil))
+	_, claims, _ := service.Verify(token)
+
+	service.Denylist = denylistStub{revoked: map[string]bool{claims.ID: true}}
+	valid, _, err := service.Verify(token)
+	if !errors.Is(err, crypto.ErrTokenRevoked) || valid {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestOpaque_Verify_IssuerAndAudience(t *testing.T) {
+	service, _ := newOpaqueService()
+	service.Config = crypto.TokenConfig{Issuer: "auth", Audience: "api"}
+
+	token, _ := service.Generate(crypto.NewClaims(testUuid, "user@example.com", nil))
+
+	service.Config = crypto.TokenConfig{Issuer: "auth", Audience: "other-api"}
+	valid, _, err := service.Verify(token)
+	if !errors.Is(err, jwt.ErrTokenInvalidAudience) || valid {
+		t.Errorf("expected ErrTokenInvalidAudience, got %v", err)
+	}
+
+	service.Config = crypto.TokenConfig{Issuer: "other-auth", Audience: "api"}
+	valid, _, err = service.Verify(token)
+	if !errors.Is(err, jwt.ErrTokenInvalidIssuer) || valid {
+		t.Errorf("expected ErrTokenInvalidIssuer, got %v", err)
+	}
+}
+
+func TestOpaque_Verify_OtherAudience(t *testing.T) {
+	service, _ := newOpaqueService()
+	service.Config = crypto.TokenConfig{Issuer: "auth", Audience: "api"}
+
+	// A token issued for another audience, as by a token exchange, is not accepted by this service
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.Audience = jwt.ClaimStrings{"downstream"}
+	token, _ := service.Generate(claims)
+
+	valid, _, err := service.Verify(token)
+	if !errors.Is(err, jwt.ErrTokenInvalidAudience) || valid {
+		t.Errorf("expected ErrTokenInvalidAudience, got %v", err)
+	}
+
+	// It is still introspected as valid, for the service it targets
+	valid, claims, err = service.VerifyAnyAudience(token)
+	if err != nil || !valid {
+		t.Errorf("expected the token to be valid for any audience, got %v", err)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "downstream" {
+		t.Errorf("expected audience [downstream], got %v", claims.Audience)
+	}
+}
@@ -0,0 +1,215 @@
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+// PASETO purposes: public tokens are signed with Ed25519, local tokens are encrypted with XChaCha20.
+const (
+	PASETO_PUBLIC = "public"
+	PASETO_LOCAL  = "local"
+)
+
+// pasetoTimeClaims are the registered claims PASETO encodes as RFC 3339 strings rather than numeric dates.
+var pasetoTimeClaims = []string{"exp", "iat", "nbf"}
+
+// PasetoService issues PASETO v4 tokens carrying the same claims as the JWTs.
+// PASETO has no algorithm header, each version and purpose uses a single algorithm,
+// so a token cannot be verified with a different algorithm than the one it was issued with.
+type PasetoService struct {
+	// Purpose is either PASETO_PUBLIC (v4.public) or PASETO_LOCAL (v4.local).
+	Purpose string
+	// Config sets the issuer, the audience and the leeway of the tokens.
+	Config TokenConfig
+	// Denylist is consulted by Verify to reject revoked tokens.
+	// Revocation is not checked when it is nil.
+	Denylist TokenDenylistInterface
+
+	secretKey    paseto.V4AsymmetricSecretKey
+	publicKey    paseto.V4AsymmetricPublicKey
+	symmetricKey paseto.V4SymmetricKey
+}
+
+// NewPublicPasetoService creates a PasetoService signing v4.public tokens with an Ed25519 key.
+// Returns a pointer to the newly created PasetoService.
+func NewPublicPasetoService(secretKey paseto.V4AsymmetricSecretKey) *PasetoService {
+	return &PasetoService{Purpose: PASETO_PUBLIC, secretKey: secretKey, publicKey: secretKey.Public()}
+}
+
+// NewLocalPasetoService creates a PasetoService encrypting v4.local tokens with a symmetric key.
+// The claims of local tokens are not readable by the clients.
+// Returns a pointer to the newly created PasetoService.
+func NewLocalPasetoService(symmetricKey paseto.V4SymmetricKey) *PasetoService {
+	return &PasetoService{Purpose: PASETO_LOCAL, symmetricKey: symmetricKey}
+}
+
+// NewPasetoServiceFromEnv creates the PasetoService configured by the environment.
+// "PASETO_PURPOSE" selects v4.public (default) or v4.local tokens, and "PASETO_KEY" holds the hex encoded key:
+// a 64 bytes Ed25519 private key for public tokens, a 32 bytes symmetric key for local tokens.
+func NewPasetoServiceFromEnv() (*PasetoService, error) {
+	key := os.Getenv("PASETO_KEY")
+	if key == "" {
+		return nil, errors.New("env variable PASETO_KEY is not set")
+	}
+
+	switch purpose := os.Getenv("PASETO_PURPOSE"); purpose {
+	case "", PASETO_PUBLIC:
+		secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(key)
+		if err != nil {
+			return nil, fmt.Errorf("env variable PASETO_KEY is not a valid Ed25519 private key: %w", err)
+		}
+		return NewPublicPasetoService(secretKey), nil
+	case PASETO_LOCAL:
+		symmetricKey, err := paseto.V4SymmetricKeyFromHex(key)
+		if err != nil {
+			return nil, fmt.Errorf("env variable PASETO_KEY is not a valid symmetric key: %w", err)
+		}
+		return NewLocalPasetoService(symmetricKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported PASETO purpose %s", purpose)
+	}
+}
+
+// GeneratePasetoKey creates a new random key for the given purpose, hex encoded as expected in "PASETO_KEY".
+func GeneratePasetoKey(purpose string) (string, error) {
+	switch purpose {
+	case PASETO_PUBLIC:
+		return paseto.NewV4AsymmetricSecretKey().ExportHex(), nil
+	case PASETO_LOCAL:
+		return paseto.NewV4SymmetricKey().ExportHex(), nil
+	default:
+		return "", fmt.Errorf("unsupported PASETO purpose %s", purpose)
+	}
+}
+
+// Generate creates a PASETO v4 token carrying the given claims, signed or encrypted according to the purpose.
+// The token expires at the date set in the claims, 15 minutes after its generation by default,
+// and carries a unique id (jti).
+// Returns the token and an error if any occurs.
+func (p *PasetoService) Generate(claims Claims) (string, error) {
+	claims, err := p.Config.prepareClaims(claims)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := claimsToPaseto(claims)
+	if err != nil {
+		return "", err
+	}
+
+	if p.Purpose == PASETO_LOCAL {
+		return token.V4Encrypt(p.symmetricKey, nil), nil
+	}
+
+	return token.V4Sign(p.secretKey, nil), nil
+}
+
+// Verify checks the signature, or decrypts, a PASETO v4 token of the purpose of the service.
+// The expiration, the issuer and the audience are enforced as for the JWTs, and a token
+// whose id is in the denylist is rejected with ErrTokenRevoked.
+// Returns a boolean indicating if the token is valid, the token claims, and an error if any occurs.
+func (p *PasetoService) Verify(tokenString string) (bool, Claims, error) {
+	return p.verify(tokenString, p.Config)
+}
+
+// VerifyAnyAudience checks a PASETO v4 token like Verify, whatever its audience.
+func (p *PasetoService) VerifyAnyAudience(tokenString string) (bool, Claims, error) {
+	return p.verify(tokenString, p.Config.anyAudience())
+}
+
+// VerifyExpired checks a PASETO v4 token like Verify, even once expired.
+func (p *PasetoService) VerifyExpired(tokenString string) (bool, Claims, error) {
+	return p.verify(tokenString, p.Config.expired())
+}
+
+// verify checks a PASETO v4 token against the given configuration.
+func (p *PasetoService) verify(tokenString string, config TokenConfig) (bool, Claims, error) {
+	parser := paseto.NewParserWithoutExpiryCheck()
+
+	var token *paseto.Token
+	var err error
+	if p.Purpose == PASETO_LOCAL {
+		token, err = parser.ParseV4Local(p.symmetricKey, tokenString, nil)
+	} else {
+		token, err = parser.ParseV4Public(p.publicKey, tokenString, nil)
+	}
+	if err != nil {
+		return false, Claims{}, err
+	}
+
+	claims, err := claimsFromPaseto(token)
+	if err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := config.validate(claims); err != nil {
+		return false, Claims{}, err
+	}
+
+	if err := checkDenylist(p.Denylist, claims.ID); err != nil {
+		return false, Claims{}, err
+	}
+
+	return true, claims, nil
+}
+
+// claimsToPaseto builds a PASETO token from the claims, writing the dates as RFC 3339 strings
+// and a single audience as a string, as expected by the PASETO registered claims.
+func claimsToPaseto(claims Claims) (*paseto.Token, error) {
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	if err := json.Unmarshal(encoded, &values); err != nil {
+		return nil, err
+	}
+
+	for _, key := range pasetoTimeClaims {
+		if value, ok := values[key].(float64); ok {
+			values[key] = time.Unix(int64(value), 0).UTC().Format(time.RFC3339)
+		}
+	}
+	if aud, ok := values["aud"].([]any); ok && len(aud) == 1 {
+		values["aud"] = aud[0]
+	}
+
+	return paseto.MakeToken(values, nil)
+}
+
+// claimsFromPaseto reads the claims of a PASETO token, converting back the dates to numeric dates.
+func claimsFromPaseto(token *paseto.Token) (Claims, error) {
+	values := token.Claims()
+
+	for _, key := range pasetoTimeClaims {
+		value, ok := values[key].(string)
+		if !ok {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Claims{}, fmt.Errorf("claim %s is not a valid date: %w", key, err)
+		}
+		values[key] = t.Unix()
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(encoded, &claims); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
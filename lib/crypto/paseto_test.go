@@ -0,0 +1,113 @@
+package crypto_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func newPasetoService(t *testing.T, purpose string) *crypto.PasetoService {
+	key, err := crypto.GeneratePasetoKey(purpose)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	os.Setenv("PASETO_PURPOSE", purpose)
+	os.Setenv("PASETO_KEY", key)
+	defer os.Unsetenv("PASETO_PURPOSE")
+	defer os.Unsetenv("PASETO_KEY")
+
+	service, err := crypto.NewPasetoServiceFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return service
+}
+
+func TestPaseto_GenerateAndVerify(t *testing.T) {
+	for purpose, prefix := range map[string]string{crypto.PASETO_PUBLIC: "v4.public.", crypto.PASETO_LOCAL: "v4.local."} {
+		service := newPasetoService(t, purpose)
+		service.Config = crypto.TokenConfig{Issuer: "auth", Audience: "api"}
+
+		token, err := service.Generate(crypto.NewClaims(testUuid, "user@example.com", []string{"USER"}))
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", purpose, err)
+		}
+		if !strings.HasPrefix(token, prefix) {
+			t.Errorf("%s: expected prefix %s, got %v", purpose, prefix, token)
+		}
+
+		valid, claims, err := service.Verify(token)
+		if err != nil || !valid {
+			t.Errorf("%s: expected token to be valid, got %v", purpose, err)
+		}
+		if claims.Subject != testUuid || claims.Email != "user@example.com" || len(claims.Roles) != 1 {
+			t.Errorf("%s: expected the claims of the user, got %+v", purpose, claims)
+		}
+		if claims.Issuer != "auth" || len(claims.Audience) != 1 || claims.Audience[0] != "api" {
+			t.Errorf("%s: expected issuer and audience, got %+v", purpose, claims)
+		}
+		if claims.ID == "" || claims.ExpiresAt == nil {
+			t.Errorf("%s: expected jti and exp claims, got %+v", purpose, claims)
+		}
+	}
+}
+
+func TestPaseto_Verify_Expired(t *testing.T) {
+	service := newPasetoService(t, crypto.PASETO_PUBLIC)
+
+	claims := crypto.NewClaims(testUuid, "user@example.com", nil)
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	token, _ := service.Generate(claims)
+
+	if valid, _, err := service.Verify(token); err == nil || valid {
+		t.Errorf("expected expired token to be rejected")
+	}
+}
+
+func TestPaseto_Verify_OtherKeyOrPurpose(t *testing.T) {
+	public := newPasetoService(t, crypto.PASETO_PUBLIC)
+	token, _ := public.Generate(crypto.NewClaims(testUuid, "user@example.com", nil))
+
+	if valid, _, err := newPasetoService(t, crypto.PASETO_PUBLIC).Verify(token); err == nil || valid {
+		t.Errorf("expected token signed with another key to be rejected")
+	}
+	if valid, _, err := newPasetoService(t, crypto.PASETO_LOCAL).Verify(token); err == nil || valid {
+		t.Errorf("expected public token to be rejected by a local service")
+	}
+}
+
+func TestPaseto_Verify_AudienceAndRevocation(t *testing.T) {
+	service := newPasetoService(t, crypto.PASETO_LOCAL)
+	service.Config = crypto.TokenConfig{Audience: "api"}
+	token, _ := service.Generate(crypto.NewClaims(testUuid, "user@example.com", nil))
+	_, claims, _ := service.Verify(token)
+
+	service.Config = crypto.TokenConfig{Audience: "other"}
+	if valid, _, err := service.Verify(token); err == nil || valid {
+		t.Errorf("expected token for another audience to be rejected")
+	}
+
+	service.Config = crypto.TokenConfig{Audience: "api"}
+	service.Denylist = denylistStub{revoked: map[string]bool{claims.ID: true}}
+	if valid, _, err := service.Verify(token); !errors.Is(err, crypto.ErrTokenRevoked) || valid {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestPaseto_InvalidEnv(t *testing.T) {
+	os.Setenv("PASETO_PURPOSE", crypto.PASETO_LOCAL)
+	os.Setenv("PASETO_KEY", "not hex")
+	defer os.Unsetenv("PASETO_PURPOSE")
+	defer os.Unsetenv("PASETO_KEY")
+
+	if _, err := crypto.NewPasetoServiceFromEnv(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -5,16 +5,182 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"math/big"
 	"os"
+	"strings"
 
+	"github.com/hhertout/twirp_auth/lib/config"
 	"golang.org/x/crypto/argon2"
 )
 
-type PasswordService struct{}
+// PHC_ARGON2ID_PREFIX starts every password hash in the PHC string format.
+const PHC_ARGON2ID_PREFIX = "$argon2id$"
+
+// ErrInvalidHash is returned when a password hash is not a valid PHC formatted Argon2id hash.
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// Argon2Params are the cost parameters of an Argon2id hash.
+// The memory is expressed in KiB, the key and salt lengths in bytes.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	KeyLength   uint32
+	SaltLength  uint32
+}
+
+// DefaultArgon2Params returns the parameters used when none are configured: 64 MiB, 1 iteration,
+// 4 lanes, a key of 32 bytes and a salt of 16 bytes.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{Memory: 64 * 1024, Iterations: 1, Parallelism: 4, KeyLength: 32, SaltLength: 16}
+}
+
+// NewArgon2ParamsFromEnv reads the Argon2id parameters of the new hashes from the "ARGON2_MEMORY" (KiB),
+// "ARGON2_ITERATIONS", "ARGON2_PARALLELISM", "ARGON2_KEY_LENGTH" and "ARGON2_SALT_LENGTH" environment variables,
+// defaulting to DefaultArgon2Params.
+func NewArgon2ParamsFromEnv() (Argon2Params, error) {
+	defaults := DefaultArgon2Params()
+
+	memory, err := config.Int("ARGON2_MEMORY", int(defaults.Memory))
+	if err != nil {
+		return Argon2Params{}, err
+	}
+	iterations, err := config.Int("ARGON2_ITERATIONS", int(defaults.Iterations))
+	if err != nil {
+		return Argon2Params{}, err
+	}
+	parallelism, err := config.Int("ARGON2_PARALLELISM", int(defaults.Parallelism))
+	if err != nil {
+		return Argon2Params{}, err
+	}
+	keyLength, err := config.Int("ARGON2_KEY_LENGTH", int(defaults.KeyLength))
+	if err != nil {
+		return Argon2Params{}, err
+	}
+	saltLength, err := config.Int("ARGON2_SALT_LENGTH", int(defaults.SaltLength))
+	if err != nil {
+		return Argon2Params{}, err
+	}
+
+	if parallelism < 1 || parallelism > 255 {
+		return Argon2Params{}, errors.New("env variable ARGON2_PARALLELISM must be between 1 and 255")
+	}
+	if memory < 8*parallelism {
+		return Argon2Params{}, errors.New("env variable ARGON2_MEMORY must be at least 8 KiB per lane")
+	}
+	if iterations < 1 {
+		return Argon2Params{}, errors.New("env variable ARGON2_ITERATIONS must be at least 1")
+	}
+	if keyLength < 16 {
+		return Argon2Params{}, errors.New("env variable ARGON2_KEY_LENGTH must be at least 16 bytes")
+	}
+	if saltLength < 8 {
+		return Argon2Params{}, errors.New("env variable ARGON2_SALT_LENGTH must be at least 8 bytes")
+	}
+
+	return Argon2Params{
+		Memory:      uint32(memory),
+		Iterations:  uint32(iterations),
+		Parallelism: uint8(parallelism),
+		KeyLength:   uint32(keyLength),
+		SaltLength:  uint32(saltLength),
+	}, nil
+}
+
+// phcHash is a PHC formatted Argon2id hash.
+type phcHash struct {
+	Params Argon2Params
+	// KeyId is the id of the pepper mixed into the password, empty without pepper.
+	KeyId string
+	Salt  []byte
+	Key   []byte
+}
+
+// encodePHC encodes an Argon2id hash in the PHC string format.
+// The pepper id is written in the keyid parameter.
+func encodePHC(hash phcHash) string {
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", hash.Params.Memory, hash.Params.Iterations, hash.Params.Parallelism)
+	if hash.KeyId != "" {
+		params += ",keyid=" + hash.KeyId
+	}
+
+	return fmt.Sprintf("%sv=%d$%s$%s$%s", PHC_ARGON2ID_PREFIX, argon2.Version, params,
+		base64.RawStdEncoding.EncodeToString(hash.Salt), base64.RawStdEncoding.EncodeToString(hash.Key))
+}
+
+// decodePHC reads the parameters, the pepper id, the salt and the key of a PHC formatted Argon2id hash.
+func decodePHC(hash string) (phcHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return phcHash{}, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return phcHash{}, ErrInvalidHash
+	}
+
+	var decoded phcHash
+	params, keyId, _ := strings.Cut(parts[3], ",keyid=")
+	if _, err := fmt.Sscanf(params, "m=%d,t=%d,p=%d", &decoded.Params.Memory, &decoded.Params.Iterations, &decoded.Params.Parallelism); err != nil {
+		return phcHash{}, ErrInvalidHash
+	}
+	decoded.KeyId = keyId
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return phcHash{}, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return phcHash{}, ErrInvalidHash
+	}
+
+	decoded.Salt = salt
+	decoded.Key = key
+	decoded.Params.KeyLength = uint32(len(key))
+	decoded.Params.SaltLength = uint32(len(salt))
+
+	return decoded, nil
+}
+
+// PasswordService hashes the passwords with Argon2id. The zero value hashes with DefaultArgon2Params.
+type PasswordService struct {
+	// Params are the parameters of the new hashes, the stored hashes are verified with their own parameters.
+	Params Argon2Params
+	// Peppers are mixed into the passwords, the stored hashes are verified with the pepper they record.
+	Peppers Peppers
+}
 
 func NewPasswordService() *PasswordService {
-	return &PasswordService{}
+	return &PasswordService{Params: DefaultArgon2Params()}
+}
+
+// NewPasswordServiceFromEnv creates a password service hashing with the parameters read by NewArgon2ParamsFromEnv
+// and the peppers read by NewPeppersFromEnv.
+func NewPasswordServiceFromEnv() (*PasswordService, error) {
+	params, err := NewArgon2ParamsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	peppers, err := NewPeppersFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasswordService{Params: params, Peppers: peppers}, nil
+}
+
+// params returns the parameters of the new hashes.
+func (p *PasswordService) params() Argon2Params {
+	if p.Params == (Argon2Params{}) {
+		return DefaultArgon2Params()
+	}
+
+	return p.Params
 }
 
 // Generate generates a random password of 16 characters using a predefined character set.
@@ -37,17 +203,77 @@ func (p *PasswordService) Generate() (string, error) {
 	return string(password), nil
 }
 
+// Hash hashes a password with Argon2id and a random salt, unique to the hash, using the parameters of the service.
+// The current pepper, if any, is mixed into the password beforehand.
+// The hash is encoded in the PHC string format, carrying its parameters, its pepper id and its salt:
+// $argon2id$v=19$m=65536,t=1,p=4,keyid=<pepper id>$<salt>$<key>
 func (p *PasswordService) Hash(password string) (string, error) {
-	salt := os.Getenv("ENCRYPT_SALT")
-	if salt == "" {
-		return "", errors.New("env variable ENCRYPT_SALT is not set")
+	params := p.params()
+
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
 	}
-	key := argon2.IDKey([]byte(password), []byte(salt), 1, 64*1024, 4, 32)
 
-	return base64.RawStdEncoding.EncodeToString(key), nil
+	peppered, err := p.Peppers.apply(p.Peppers.Current, password)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(peppered, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return encodePHC(phcHash{Params: params, KeyId: p.Peppers.Current, Salt: salt, Key: key}), nil
 }
 
+// Verify checks a password against a PHC formatted Argon2id hash, using the parameters, the pepper and the salt of the hash.
+// The bcrypt, PBKDF2-SHA256 and scrypt hashes imported from other systems are recognised by their prefix.
+// Legacy hashes, a bare base64 key derived with the global "ENCRYPT_SALT", are still accepted.
+// Returns ErrUnknownPepper if the pepper of the hash is no longer configured.
 func (p *PasswordService) Verify(password string, hash string) (bool, error) {
+	if !strings.HasPrefix(hash, PHC_ARGON2ID_PREFIX) {
+		if valid, imported, err := verifyImported(password, hash); imported {
+			return valid, err
+		}
+
+		return verifyLegacy(password, hash)
+	}
+
+	decoded, err := decodePHC(hash)
+	if err != nil {
+		return false, err
+	}
+
+	peppered, err := p.Peppers.apply(decoded.KeyId, password)
+	if err != nil {
+		return false, err
+	}
+
+	params := decoded.Params
+	hashToCompare := argon2.IDKey(peppered, decoded.Salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	res := subtle.ConstantTimeCompare(hashToCompare, decoded.Key)
+
+	return res == 1, nil
+}
+
+// NeedsRehash reports whether a hash must be replaced by a new hash of the password,
+// after a successful verification: imported and legacy global-salt hashes are upgraded to the PHC format,
+// and the hashes made with other parameters or another pepper than those of the service are upgraded to them.
+func (p *PasswordService) NeedsRehash(hash string) bool {
+	if !strings.HasPrefix(hash, PHC_ARGON2ID_PREFIX) {
+		return true
+	}
+
+	decoded, err := decodePHC(hash)
+	if err != nil {
+		return true
+	}
+
+	return decoded.Params != p.params() || decoded.KeyId != p.Peppers.Current
+}
+
+// verifyLegacy checks a password against a hash made with the global "ENCRYPT_SALT",
+// before the hashes carried their own salt.
+func verifyLegacy(password string, hash string) (bool, error) {
 	salt := os.Getenv("ENCRYPT_SALT")
 	if salt == "" {
 		return false, errors.New("env variable ENCRYPT_SALT is not set")
@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// importedHashFormats are the formats of the hashes imported from other systems, recognised by their prefix.
+// They are only verified, the passwords are rehashed with Argon2id on the next login of their user.
+var importedHashFormats = []struct {
+	prefix string
+	verify func(password string, hash string) (bool, error)
+}{
+	{"$2a$", verifyBcrypt},
+	{"$2b$", verifyBcrypt},
+	{"$2y$", verifyBcrypt},
+	{"$pbkdf2-sha256$", verifyPasslibPBKDF2},
+	{"pbkdf2_sha256$", verifyDjangoPBKDF2},
+	{"$scrypt$", verifyPasslibScrypt},
+}
+
+// verifyImported checks a password against a hash imported from another system.
+// The second value is false if the format of the hash is not recognised.
+func verifyImported(password string, hash string) (bool, bool, error) {
+	for _, format := range importedHashFormats {
+		if strings.HasPrefix(hash, format.prefix) {
+			valid, err := format.verify(password, hash)
+			return valid, true, err
+		}
+	}
+
+	return false, false, nil
+}
+
+// verifyBcrypt checks a password against a bcrypt hash: $2b$<cost>$<salt and key>
+func verifyBcrypt(password string, hash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	return true, nil
+}
+
+// verifyPasslibPBKDF2 checks a password against a PBKDF2-SHA256 hash in the passlib format:
+// $pbkdf2-sha256$<iterations>$<salt>$<key>, salt and key encoded in adapted base64.
+func verifyPasslibPBKDF2(password string, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return false, ErrInvalidHash
+	}
+
+	iterations, err := strconv.Atoi(parts[2])
+	if err != nil || iterations < 1 {
+		return false, ErrInvalidHash
+	}
+
+	salt, err := decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	key, err := decodeAdaptedBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	hashToCompare := pbkdf2.Key([]byte(password), salt, iterations, len(key), sha256.New)
+
+	return subtle.ConstantTimeCompare(hashToCompare, key) == 1, nil
+}
+
+// verifyDjangoPBKDF2 checks a password against a PBKDF2-SHA256 hash in the Django format:
+// pbkdf2_sha256$<iterations>$<salt>$<key>, the salt in clear and the key in base64.
+func verifyDjangoPBKDF2(password string, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 {
+		return false, ErrInvalidHash
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false, ErrInvalidHash
+	}
+
+	key, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	hashToCompare := pbkdf2.Key([]byte(password), []byte(parts[2]), iterations, len(key), sha256.New)
+
+	return subtle.ConstantTimeCompare(hashToCompare, key) == 1, nil
+}
+
+// verifyPasslibScrypt checks a password against a scrypt hash in the passlib format:
+// $scrypt$ln=<log2 N>,r=<block size>,p=<parallelism>$<salt>$<key>, salt and key encoded in adapted base64.
+func verifyPasslibScrypt(password string, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return false, ErrInvalidHash
+	}
+
+	var ln, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &r, &p); err != nil || ln < 1 || ln > 30 {
+		return false, ErrInvalidHash
+	}
+
+	salt, err := decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	key, err := decodeAdaptedBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	hashToCompare, err := scrypt.Key([]byte(password), salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	return subtle.ConstantTimeCompare(hashToCompare, key) == 1, nil
+}
+
+// decodeAdaptedBase64 decodes the adapted base64 of passlib, which uses "." instead of "+" and no padding.
+func decodeAdaptedBase64(value string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(value, ".", "+"))
+}
@@ -0,0 +1,78 @@
+package crypto_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// adaptedBase64 encodes a value in the adapted base64 of passlib.
+func adaptedBase64(value []byte) string {
+	return strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(value), "+", ".")
+}
+
+func importedHashes(t *testing.T) map[string]string {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	salt := []byte("0123456789abcdef")
+	pbkdf2Key := pbkdf2.Key([]byte("password123"), salt, 1000, 32, sha256.New)
+	djangoKey := pbkdf2.Key([]byte("password123"), []byte("djangosalt"), 1000, 32, sha256.New)
+	scryptKey, err := scrypt.Key([]byte("password123"), salt, 1<<10, 8, 1, 32)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return map[string]string{
+		"bcrypt":         string(bcryptHash),
+		"passlib pbkdf2": "$pbkdf2-sha256$1000$" + adaptedBase64(salt) + "$" + adaptedBase64(pbkdf2Key),
+		"django pbkdf2":  "pbkdf2_sha256$1000$djangosalt$" + base64.StdEncoding.EncodeToString(djangoKey),
+		"passlib scrypt": "$scrypt$ln=10,r=8,p=1$" + adaptedBase64(salt) + "$" + adaptedBase64(scryptKey),
+	}
+}
+
+func TestVerify_ImportedHashes(t *testing.T) {
+	passwordService := crypto.NewPasswordService()
+
+	for format, hash := range importedHashes(t) {
+		valid, err := passwordService.Verify("password123", hash)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", format, err)
+		}
+		if !valid {
+			t.Errorf("%s: expected valid password, got invalid", format)
+		}
+
+		valid, err = passwordService.Verify("wrongpassword", hash)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", format, err)
+		}
+		if valid {
+			t.Errorf("%s: expected invalid password, got valid", format)
+		}
+
+		if !passwordService.NeedsRehash(hash) {
+			t.Errorf("%s: expected the imported hash to need a rehash", format)
+		}
+	}
+}
+
+func TestVerify_MalformedImportedHash(t *testing.T) {
+	passwordService := crypto.NewPasswordService()
+
+	for _, hash := range []string{"$pbkdf2-sha256$abc$salt$key", "pbkdf2_sha256$1000$salt", "$scrypt$ln=x$salt$key"} {
+		_, err := passwordService.Verify("password123", hash)
+		if !errors.Is(err, crypto.ErrInvalidHash) {
+			t.Errorf("expected ErrInvalidHash for %s, got %v", hash, err)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package crypto_test
 
 import (
+	"encoding/base64"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hhertout/twirp_auth/lib/crypto"
+	"golang.org/x/crypto/argon2"
 )
 
 func TestGeneratePassword(t *testing.T) {
@@ -34,20 +38,28 @@ func TestHash(t *testing.T) {
 	}
 }
 
-func TestHash_NoSalt(t *testing.T) {
+func TestHash_PHC(t *testing.T) {
 	os.Unsetenv("ENCRYPT_SALT")
 
 	passwordService := crypto.PasswordService{}
 	hash, err := passwordService.Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
 
-	if err == nil {
-		t.Errorf("expected error, got nil")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=1,p=4$") {
+		t.Errorf("expected a PHC formatted argon2id hash, got %v", hash)
 	}
-	if err.Error() != "env variable ENCRYPT_SALT is not set" {
-		t.Errorf("expected error 'env variable ENCRYPT_SALT is not set', got %v", err)
+	if parts := strings.Split(hash, "$"); len(parts) != 6 {
+		t.Errorf("expected 6 parts in the hash, got %d", len(parts))
+	}
+
+	other, err := passwordService.Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
 	}
-	if hash != "" {
-		t.Errorf("expected empty hash, got %v", hash)
+	if hash == other {
+		t.Errorf("expected different hashes for the same password, got %v twice", hash)
 	}
 }
 
@@ -92,16 +104,32 @@ func TestVerify_InvalidHash(t *testing.T) {
 	}
 }
 
-func TestVerify_NoSalt(t *testing.T) {
-	os.Unsetenv("ENCRYPT_SALT")
+func TestVerify_Legacy(t *testing.T) {
+	os.Setenv("ENCRYPT_SALT", "test_salt")
+	defer os.Unsetenv("ENCRYPT_SALT")
+
+	// Hash of "password123" with the global salt "test_salt", before the PHC format
+	legacy := base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("password123"), []byte("test_salt"), 1, 64*1024, 4, 32))
 
 	passwordService := crypto.PasswordService{}
-	hash, err := passwordService.Hash("password123")
-	if err == nil {
-		t.Errorf("expected error, got nil")
+	valid, err := passwordService.Verify("password123", legacy)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid password, got invalid")
 	}
 
-	valid, err := passwordService.Verify("password123", hash)
+	if !passwordService.NeedsRehash(legacy) {
+		t.Errorf("expected the legacy hash to need a rehash")
+	}
+}
+
+func TestVerify_NoSalt(t *testing.T) {
+	os.Unsetenv("ENCRYPT_SALT")
+
+	passwordService := crypto.PasswordService{}
+	valid, err := passwordService.Verify("password123", "bGVnYWN5aGFzaA")
 
 	if err == nil {
 		t.Errorf("expected error, got nil")
@@ -113,3 +141,84 @@ func TestVerify_NoSalt(t *testing.T) {
 		t.Errorf("expected invalid password, got valid")
 	}
 }
+
+func TestVerify_MalformedPHC(t *testing.T) {
+	passwordService := crypto.PasswordService{}
+	_, err := passwordService.Verify("password123", "$argon2id$v=19$m=abc$salt$key")
+	if !errors.Is(err, crypto.ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	passwordService := crypto.PasswordService{}
+	hash, err := passwordService.Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if passwordService.NeedsRehash(hash) {
+		t.Errorf("expected a fresh hash not to need a rehash")
+	}
+}
+
+func TestNewArgon2ParamsFromEnv(t *testing.T) {
+	params, err := crypto.NewArgon2ParamsFromEnv()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if params != crypto.DefaultArgon2Params() {
+		t.Errorf("expected the default parameters, got %v", params)
+	}
+
+	os.Setenv("ARGON2_MEMORY", "32768")
+	os.Setenv("ARGON2_ITERATIONS", "3")
+	os.Setenv("ARGON2_PARALLELISM", "2")
+	defer os.Unsetenv("ARGON2_MEMORY")
+	defer os.Unsetenv("ARGON2_ITERATIONS")
+	defer os.Unsetenv("ARGON2_PARALLELISM")
+
+	params, err = crypto.NewArgon2ParamsFromEnv()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if params.Memory != 32768 || params.Iterations != 3 || params.Parallelism != 2 || params.KeyLength != 32 {
+		t.Errorf("expected m=32768,t=3,p=2 and a key of 32 bytes, got %v", params)
+	}
+}
+
+func TestNewArgon2ParamsFromEnv_Invalid(t *testing.T) {
+	os.Setenv("ARGON2_PARALLELISM", "0")
+	defer os.Unsetenv("ARGON2_PARALLELISM")
+
+	_, err := crypto.NewArgon2ParamsFromEnv()
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestNeedsRehash_OutdatedParams(t *testing.T) {
+	weak := crypto.PasswordService{Params: crypto.Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16}}
+	hash, err := weak.Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Errorf("expected the parameters in the hash, got %v", hash)
+	}
+
+	passwordService := crypto.NewPasswordService()
+	if !passwordService.NeedsRehash(hash) {
+		t.Errorf("expected a hash with outdated parameters to need a rehash")
+	}
+
+	// The parameters of the stored hash are used for the verification
+	valid, err := passwordService.Verify("password123", hash)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid password, got invalid")
+	}
+}
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// ErrUnknownPepper is returned when a hash was made with a pepper that is no longer configured.
+var ErrUnknownPepper = errors.New("unknown pepper")
+
+// pepperIdPattern restricts the pepper ids to the characters allowed in a PHC parameter value.
+var pepperIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Peppers are the server-side secrets mixed into the password hashes, kept out of the database
+// so that a dump of the database alone is not enough to brute-force the passwords.
+// Each hash records the id of its pepper, several peppers stay valid at once during a rotation.
+type Peppers struct {
+	// Current is the id of the pepper of the new hashes, empty to hash without pepper.
+	Current string
+	// Keys are the peppers by id, the current one and those still verifying the stored hashes.
+	Keys map[string][]byte
+}
+
+// NewPeppersFromEnv reads the peppers from the "PASSWORD_PEPPERS" environment variable,
+// a comma separated list of <id>:<hex secret> pairs, and the id of the current one from "PASSWORD_PEPPER_ID".
+// Without peppers, the passwords are hashed without pepper.
+func NewPeppersFromEnv() (Peppers, error) {
+	peppers := Peppers{Current: os.Getenv("PASSWORD_PEPPER_ID"), Keys: map[string][]byte{}}
+
+	for _, pair := range config.List("PASSWORD_PEPPERS") {
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || !pepperIdPattern.MatchString(id) {
+			return Peppers{}, fmt.Errorf("env variable PASSWORD_PEPPERS has an invalid pepper %q", id)
+		}
+
+		key, err := hex.DecodeString(secret)
+		if err != nil || len(key) < 16 {
+			return Peppers{}, fmt.Errorf("env variable PASSWORD_PEPPERS has an invalid secret for pepper %s, expected at least 16 bytes in hex", id)
+		}
+
+		peppers.Keys[id] = key
+	}
+
+	if peppers.Current != "" {
+		if _, ok := peppers.Keys[peppers.Current]; !ok {
+			return Peppers{}, fmt.Errorf("env variable PASSWORD_PEPPER_ID refers to the unknown pepper %s", peppers.Current)
+		}
+	}
+	if peppers.Current == "" && len(peppers.Keys) > 0 {
+		return Peppers{}, errors.New("env variable PASSWORD_PEPPER_ID is required with PASSWORD_PEPPERS")
+	}
+
+	return peppers, nil
+}
+
+// apply mixes the pepper with the given id into the password, with HMAC-SHA256.
+// The password is returned as is when the id is empty.
+func (p Peppers) apply(id string, password string) ([]byte, error) {
+	if id == "" {
+		return []byte(password), nil
+	}
+
+	key, ok := p.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPepper, id)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(password))
+
+	return mac.Sum(nil), nil
+}
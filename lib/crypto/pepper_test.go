@@ -0,0 +1,121 @@
+package crypto_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+const (
+	testPepperV1 = "000102030405060708090a0b0c0d0e0f"
+	testPepperV2 = "101112131415161718191a1b1c1d1e1f"
+)
+
+func TestNewPeppersFromEnv(t *testing.T) {
+	os.Setenv("PASSWORD_PEPPERS", "v1:"+testPepperV1+",v2:"+testPepperV2)
+	os.Setenv("PASSWORD_PEPPER_ID", "v2")
+	defer os.Unsetenv("PASSWORD_PEPPERS")
+	defer os.Unsetenv("PASSWORD_PEPPER_ID")
+
+	peppers, err := crypto.NewPeppersFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if peppers.Current != "v2" {
+		t.Errorf("expected current pepper v2, got %v", peppers.Current)
+	}
+	if len(peppers.Keys) != 2 {
+		t.Errorf("expected 2 peppers, got %d", len(peppers.Keys))
+	}
+}
+
+func TestNewPeppersFromEnv_Invalid(t *testing.T) {
+	defer os.Unsetenv("PASSWORD_PEPPERS")
+	defer os.Unsetenv("PASSWORD_PEPPER_ID")
+
+	for _, env := range [][2]string{
+		{"v1:" + testPepperV1, ""},
+		{"v1:" + testPepperV1, "v2"},
+		{"v1:abcd", "v1"},
+		{"v$1:" + testPepperV1, "v$1"},
+	} {
+		os.Setenv("PASSWORD_PEPPERS", env[0])
+		os.Setenv("PASSWORD_PEPPER_ID", env[1])
+
+		if _, err := crypto.NewPeppersFromEnv(); err == nil {
+			t.Errorf("expected error for PASSWORD_PEPPERS=%s PASSWORD_PEPPER_ID=%s, got nil", env[0], env[1])
+		}
+	}
+}
+
+func pepperedService(t *testing.T, current string) *crypto.PasswordService {
+	os.Setenv("PASSWORD_PEPPERS", "v1:"+testPepperV1+",v2:"+testPepperV2)
+	os.Setenv("PASSWORD_PEPPER_ID", current)
+	defer os.Unsetenv("PASSWORD_PEPPERS")
+	defer os.Unsetenv("PASSWORD_PEPPER_ID")
+
+	passwordService, err := crypto.NewPasswordServiceFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return passwordService
+}
+
+func TestHash_Pepper(t *testing.T) {
+	passwordService := pepperedService(t, "v1")
+
+	hash, err := passwordService.Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !strings.Contains(hash, ",keyid=v1$") {
+		t.Errorf("expected the pepper id in the hash, got %v", hash)
+	}
+
+	valid, err := passwordService.Verify("password123", hash)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid password, got invalid")
+	}
+
+	// Without the pepper, the hash cannot be verified
+	_, err = crypto.NewPasswordService().Verify("password123", hash)
+	if !errors.Is(err, crypto.ErrUnknownPepper) {
+		t.Errorf("expected ErrUnknownPepper, got %v", err)
+	}
+}
+
+func TestNeedsRehash_PepperRotation(t *testing.T) {
+	hash, err := pepperedService(t, "v1").Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	rotated := pepperedService(t, "v2")
+	valid, err := rotated.Verify("password123", hash)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid password, got invalid")
+	}
+	if !rotated.NeedsRehash(hash) {
+		t.Errorf("expected a hash with the previous pepper to need a rehash")
+	}
+
+	// Unpeppered hashes are peppered on the next login
+	unpeppered, err := crypto.NewPasswordService().Hash("password123")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !rotated.NeedsRehash(unpeppered) {
+		t.Errorf("expected an unpeppered hash to need a rehash")
+	}
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// RefreshTokenLifetime is the default duration a refresh token stays valid once issued.
+const RefreshTokenLifetime = 30 * 24 * time.Hour
+
+type RefreshTokenService struct{}
+
+// NewRefreshTokenService creates a new instance of RefreshTokenService.
+// Returns a pointer to the newly created RefreshTokenService.
+func NewRefreshTokenService() *RefreshTokenService {
+	return &RefreshTokenService{}
+}
+
+// Generate creates a new opaque refresh token made of 32 random bytes.
+// Returns the token to hand over to the client, its hash to store and an error if any occurs.
+func (r *RefreshTokenService) Generate() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, r.Hash(token), nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of a refresh token.
+// Refresh tokens are only stored hashed, so a database leak does not expose usable tokens.
+func (r *RefreshTokenService) Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,54 @@
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/crypto"
+)
+
+func TestGenerateRefreshToken(t *testing.T) {
+	refreshService := crypto.NewRefreshTokenService()
+	token, hash, err := refreshService.Generate()
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if token == "" {
+		t.Errorf("expected non-empty token, got empty string")
+	}
+	if len(hash) != 64 {
+		t.Errorf("expected hash length 64, got %d", len(hash))
+	}
+	if hash != refreshService.Hash(token) {
+		t.Errorf("expected hash to match the hash of the token")
+	}
+}
+
+func TestGenerateRefreshToken_Unique(t *testing.T) {
+	refreshService := crypto.NewRefreshTokenService()
+	first, _, err := refreshService.Generate()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	second, _, err := refreshService.Generate()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected two different tokens, got %v twice", first)
+	}
+}
+
+func TestHashRefreshToken_DoesNotLeakToken(t *testing.T) {
+	refreshService := crypto.NewRefreshTokenService()
+	token, hash, err := refreshService.Generate()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if hash == token {
+		t.Errorf("expected hash to differ from the token")
+	}
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Senders selectable with the "NOTIFY_SENDER" environment variable.
+const (
+	SENDER_STDOUT = "stdout"
+	SENDER_SMTP   = "smtp"
+)
+
+// ErrInvalidHeader is returned when the recipient or the subject of a message contains a line break,
+// which would let a header be injected into the message.
+var ErrInvalidHeader = errors.New("notification header contains a line break")
+
+// Message is a notification sent to a user.
+type Message struct {
+	// To is the address of the recipient.
+	To      string
+	Subject string
+	// Body is the plain text content of the message.
+	Body string
+}
+
+// Validate checks that the message has a recipient and that its headers cannot be used to inject other headers.
+func (m Message) Validate() error {
+	if m.To == "" {
+		return errors.New("notification has no recipient")
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+
+	return nil
+}
+
+// SenderInterface defines the methods required to deliver the notifications to the users.
+type SenderInterface interface {
+	// Send delivers a message to its recipient.
+	//
+	// Parameters:
+	// - ctx: the context of the delivery, its deadline bounds the delivery.
+	// - message: the message to deliver.
+	//
+	// Returns:
+	// - An error if the message is invalid or could not be delivered.
+	Send(ctx context.Context, message Message) error
+}
+
+// NewSenderFromEnv creates the sender configured by the "NOTIFY_SENDER" environment variable:
+// "smtp" relays the messages to the SMTP server configured by the "SMTP_*" variables,
+// "stdout" prints them in full and is only allowed when "GO_ENV" is "development",
+// since the messages carry secrets such as the password reset links.
+// There is no default sender, an empty "NOTIFY_SENDER" is refused.
+func NewSenderFromEnv() (SenderInterface, error) {
+	switch sender := os.Getenv("NOTIFY_SENDER"); sender {
+	case "":
+		return nil, errors.New("env variable NOTIFY_SENDER is required to send notifications")
+	case SENDER_STDOUT:
+		if os.Getenv("GO_ENV") != "development" {
+			return nil, errors.New("the stdout notification sender is only allowed when GO_ENV is development")
+		}
+		return NewWriterSender(os.Stdout), nil
+	case SENDER_SMTP:
+		return NewSMTPSenderFromEnv()
+	default:
+		return nil, fmt.Errorf("unsupported notification sender %q", sender)
+	}
+}
@@ -0,0 +1,124 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/notify"
+)
+
+func TestWriterSender(t *testing.T) {
+	var out bytes.Buffer
+	sender := notify.NewWriterSender(&out)
+
+	err := sender.Send(context.Background(), notify.Message{
+		To:      "user@example.com",
+		Subject: "Reset your password",
+		Body:    "https://example.com/reset?token=abc",
+	})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	written := out.String()
+	if !strings.Contains(written, "To: user@example.com\n") {
+		t.Errorf("expected the recipient to be written, got %q", written)
+	}
+	if !strings.Contains(written, "https://example.com/reset?token=abc") {
+		t.Errorf("expected the body to be written, got %q", written)
+	}
+}
+
+func TestWriterSender_CanceledContext(t *testing.T) {
+	var out bytes.Buffer
+	sender := notify.NewWriterSender(&out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := sender.Send(ctx, notify.Message{To: "user@example.com"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q", out.String())
+	}
+}
+
+func TestMessage_Validate(t *testing.T) {
+	valid := notify.Message{To: "user@example.com", Subject: "Reset your password"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := (notify.Message{Subject: "Reset your password"}).Validate(); err == nil {
+		t.Errorf("expected an error for a message without recipient")
+	}
+
+	injected := []notify.Message{
+		{To: "user@example.com\r\nBcc: attacker@example.com", Subject: "Reset your password"},
+		{To: "user@example.com", Subject: "Reset\nBcc: attacker@example.com"},
+	}
+	for _, message := range injected {
+		if err := message.Validate(); !errors.Is(err, notify.ErrInvalidHeader) {
+			t.Errorf("expected ErrInvalidHeader, got %v", err)
+		}
+	}
+}
+
+func TestNewSenderFromEnv(t *testing.T) {
+	os.Unsetenv("NOTIFY_SENDER")
+	if _, err := notify.NewSenderFromEnv(); err == nil {
+		t.Errorf("expected an error without sender")
+	}
+
+	os.Setenv("NOTIFY_SENDER", "pigeon")
+	defer os.Unsetenv("NOTIFY_SENDER")
+	if _, err := notify.NewSenderFromEnv(); err == nil {
+		t.Errorf("expected an error for an unsupported sender")
+	}
+}
+
+func TestNewSenderFromEnv_Stdout(t *testing.T) {
+	os.Setenv("NOTIFY_SENDER", "stdout")
+	defer os.Unsetenv("NOTIFY_SENDER")
+
+	os.Unsetenv("GO_ENV")
+	if _, err := notify.NewSenderFromEnv(); err == nil {
+		t.Errorf("expected the stdout sender to be refused outside of development")
+	}
+
+	os.Setenv("GO_ENV", "development")
+	defer os.Unsetenv("GO_ENV")
+	sender, err := notify.NewSenderFromEnv()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, ok := sender.(*notify.WriterSender); !ok {
+		t.Errorf("expected the stdout sender, got %T", sender)
+	}
+}
+
+func TestNewSMTPSenderFromEnv(t *testing.T) {
+	os.Setenv("SMTP_HOST", "smtp.example.com")
+	defer os.Unsetenv("SMTP_HOST")
+
+	if _, err := notify.NewSMTPSenderFromEnv(); err == nil {
+		t.Errorf("expected an error without SMTP_FROM")
+	}
+
+	os.Setenv("SMTP_FROM", "no-reply@example.com")
+	defer os.Unsetenv("SMTP_FROM")
+
+	sender, err := notify.NewSMTPSenderFromEnv()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if sender.Port != 587 {
+		t.Errorf("expected the submission port by default, got %d", sender.Port)
+	}
+}
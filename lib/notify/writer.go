@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WriterSender writes the messages to a writer instead of delivering them.
+// The messages are written in full, secrets included, so it must only be used in development.
+type WriterSender struct {
+	Writer io.Writer
+	mu     sync.Mutex
+}
+
+// NewWriterSender creates a sender writing the messages to the writer.
+func NewWriterSender(w io.Writer) *WriterSender {
+	return &WriterSender{Writer: w}
+}
+
+func (s *WriterSender) Send(ctx context.Context, message Message) error {
+	if err := message.Validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.Writer, "To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+	return err
+}
@@ -0,0 +1,125 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomFilterMagic starts the files of the Bloom filters, followed by the format version.
+var bloomFilterMagic = []byte("PWBF\x01")
+
+// ErrInvalidBloomFilter is returned when a file is not a Bloom filter written by WriteTo.
+var ErrInvalidBloomFilter = errors.New("invalid bloom filter")
+
+// BloomFilter is a compact set of breached SHA-1 hashes. A password absent from the breaches
+// may be reported as breached with the false positive rate of the filter, a breached password is always reported.
+// The positions of a hash are derived from the hash itself, already uniformly distributed.
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint32
+}
+
+// NewBloomFilter creates an empty filter sized for the number of hashes and the false positive rate.
+func NewBloomFilter(count uint64, falsePositiveRate float64) *BloomFilter {
+	count = max(count, 1)
+	size := uint64(math.Ceil(-float64(count) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	hashes := uint32(max(math.Round(float64(size)/float64(count)*math.Ln2), 1))
+
+	return &BloomFilter{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+}
+
+// LoadBloomFilter reads a filter from a file written by WriteTo.
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadBloomFilter(bufio.NewReader(file))
+}
+
+// ReadBloomFilter reads a filter written by WriteTo.
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	magic := make([]byte, len(bloomFilterMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != string(bloomFilterMagic) {
+		return nil, ErrInvalidBloomFilter
+	}
+
+	var header struct {
+		Size   uint64
+		Hashes uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || header.Size == 0 || header.Hashes == 0 {
+		return nil, ErrInvalidBloomFilter
+	}
+
+	bits := make([]uint64, (header.Size+63)/64)
+	if err := binary.Read(r, binary.BigEndian, bits); err != nil {
+		return nil, ErrInvalidBloomFilter
+	}
+
+	return &BloomFilter{bits: bits, size: header.Size, hashes: header.Hashes}, nil
+}
+
+// WriteTo writes the filter, to be read back by ReadBloomFilter.
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	buffered := bufio.NewWriter(w)
+
+	if _, err := buffered.Write(bloomFilterMagic); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(buffered, binary.BigEndian, f.size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(buffered, binary.BigEndian, f.hashes); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(buffered, binary.BigEndian, f.bits); err != nil {
+		return 0, err
+	}
+
+	return int64(len(bloomFilterMagic) + 12 + 8*len(f.bits)), buffered.Flush()
+}
+
+// Add adds a SHA-1 hash to the filter.
+func (f *BloomFilter) Add(hash [sha1.Size]byte) {
+	for _, position := range f.positions(hash) {
+		f.bits[position/64] |= 1 << (position % 64)
+	}
+}
+
+// Contains checks if a SHA-1 hash may have been added to the filter.
+func (f *BloomFilter) Contains(hash [sha1.Size]byte) bool {
+	for _, position := range f.positions(hash) {
+		if f.bits[position/64]&(1<<(position%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *BloomFilter) IsBreached(password string) (bool, error) {
+	return f.Contains(sha1.Sum([]byte(password))), nil
+}
+
+// positions returns the bits of a hash, by double hashing over two halves of the SHA-1.
+func (f *BloomFilter) positions(hash [sha1.Size]byte) []uint64 {
+	h1 := binary.BigEndian.Uint64(hash[0:8])
+	h2 := binary.BigEndian.Uint64(hash[8:16]) | 1
+
+	positions := make([]uint64, f.hashes)
+	for i := range positions {
+		positions[i] = (h1 + uint64(i)*h2) % f.size
+	}
+
+	return positions
+}
@@ -0,0 +1,63 @@
+package password_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/password"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := password.NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add(sha1.Sum([]byte(fmt.Sprintf("breached-%d", i))))
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !filter.Contains(sha1.Sum([]byte(fmt.Sprintf("breached-%d", i)))) {
+			t.Fatalf("expected breached-%d in the filter", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.Contains(sha1.Sum([]byte(fmt.Sprintf("safe-%d", i)))) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("expected about 1%% of false positives, got %d out of 10000", falsePositives)
+	}
+}
+
+func TestBloomFilter_WriteRead(t *testing.T) {
+	filter := password.NewBloomFilter(10, 0.001)
+	filter.Add(sha1.Sum([]byte("password123")))
+
+	var buffer bytes.Buffer
+	if _, err := filter.WriteTo(&buffer); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	read, err := password.ReadBloomFilter(&buffer)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	breached, err := read.IsBreached("password123")
+	if err != nil || !breached {
+		t.Errorf("expected password123 to be breached, got %v, %v", breached, err)
+	}
+	if breached, _ := read.IsBreached("correct horse battery staple"); breached {
+		t.Errorf("expected correct horse battery staple not to be breached")
+	}
+}
+
+func TestReadBloomFilter_Invalid(t *testing.T) {
+	_, err := password.ReadBloomFilter(bytes.NewReader([]byte("not a filter")))
+	if err != password.ErrInvalidBloomFilter {
+		t.Errorf("expected ErrInvalidBloomFilter, got %v", err)
+	}
+}
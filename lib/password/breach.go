@@ -0,0 +1,211 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// HIBP_PREFIX_LENGTH is the number of hexadecimal characters of the SHA-1 prefixes naming the range files.
+const HIBP_PREFIX_LENGTH = 5
+
+// BreachCheckerInterface defines the methods required to screen the passwords against the known breach corpora.
+// The implementations work offline, the passwords never leave the service.
+type BreachCheckerInterface interface {
+	// IsBreached checks if a password appears in the breach corpora.
+	//
+	// Parameters:
+	// - password: the password to screen.
+	//
+	// Returns:
+	// - A boolean indicating if the password has been breached.
+	// - An error if any occurs during the lookup.
+	IsBreached(password string) (bool, error)
+}
+
+// NewBreachCheckerFromEnv creates the breach checker configured by the "PASSWORD_BREACH_FILTER" environment variable,
+// the path of a Bloom filter built by the breach-filter command, or by "PASSWORD_BREACH_RANGES", a directory
+// of HIBP range files whose hashes seen less than "PASSWORD_BREACH_MIN_COUNT" times (default 1) are ignored.
+// Returns nil when none is configured.
+func NewBreachCheckerFromEnv() (BreachCheckerInterface, error) {
+	if path := os.Getenv("PASSWORD_BREACH_FILTER"); path != "" {
+		return LoadBloomFilter(path)
+	}
+
+	if directory := os.Getenv("PASSWORD_BREACH_RANGES"); directory != "" {
+		minCount, err := config.Int("PASSWORD_BREACH_MIN_COUNT", 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRangeChecker(directory, minCount)
+	}
+
+	return nil, nil
+}
+
+// RangeChecker screens the passwords against a local copy of the HIBP Pwned Passwords range files:
+// a directory holding one file per SHA-1 prefix of 5 hexadecimal characters, named after the prefix
+// with an optional ".txt" extension, listing the <suffix>:<count> of the breached hashes of the prefix.
+// Only the file of the prefix of the password is read.
+type RangeChecker struct {
+	Directory string
+	// MinCount ignores the hashes seen less than MinCount times in the breaches.
+	MinCount int
+}
+
+// NewRangeChecker creates a checker reading the range files of the directory.
+func NewRangeChecker(directory string, minCount int) (*RangeChecker, error) {
+	info, err := os.Stat(directory)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", directory)
+	}
+
+	return &RangeChecker{Directory: directory, MinCount: minCount}, nil
+}
+
+func (c *RangeChecker) IsBreached(password string) (bool, error) {
+	hash := sha1Hex(password)
+	prefix, suffix := hash[:HIBP_PREFIX_LENGTH], hash[HIBP_PREFIX_LENGTH:]
+
+	file, err := c.openRange(prefix)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	found := false
+	err = scanHashes(file, func(line string, count int) error {
+		if count >= c.MinCount && strings.EqualFold(line, suffix) {
+			found = true
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// openRange opens the range file of a prefix, with or without the ".txt" extension.
+func (c *RangeChecker) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(c.Directory, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Open(filepath.Join(c.Directory, prefix+".txt"))
+	}
+
+	return file, err
+}
+
+// ReadDump reads the SHA-1 hashes of a breach dump seen at least minCount times, calling fn for each of them.
+// The dump is either a file of <hash>:<count> lines, such as the HIBP Pwned Passwords ordered by hash,
+// or a directory of range files of <suffix>:<count> lines named after their prefix.
+func ReadDump(path string, minCount int, fn func(hash [sha1.Size]byte) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return readDumpFile(path, "", minCount, fn)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		prefix := strings.TrimSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || len(prefix) != HIBP_PREFIX_LENGTH {
+			continue
+		}
+
+		if err := readDumpFile(filepath.Join(path, entry.Name()), prefix, minCount, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readDumpFile reads the hashes of a file of the dump, prefixed with the prefix of a range file.
+func readDumpFile(path string, prefix string, minCount int, fn func(hash [sha1.Size]byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return scanHashes(file, func(line string, count int) error {
+		if count < minCount {
+			return nil
+		}
+
+		value := prefix + line
+		var hash [sha1.Size]byte
+		if len(value) != 2*sha1.Size {
+			return fmt.Errorf("%s: invalid hash %s", path, value)
+		}
+		if _, err := hex.Decode(hash[:], []byte(value)); err != nil {
+			return fmt.Errorf("%s: invalid hash %s", path, value)
+		}
+
+		return fn(hash)
+	})
+}
+
+// scanHashes calls fn with the hash and the count of each <hash>:<count> line of a reader.
+// The lines without count are counted once. Returning io.EOF from fn stops the scan without error.
+func scanHashes(r io.Reader, fn func(hash string, count int) error) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, rawCount, found := strings.Cut(line, ":")
+		count := 1
+		if found {
+			c, err := strconv.Atoi(rawCount)
+			if err != nil {
+				return fmt.Errorf("invalid count in line %q", line)
+			}
+			count = c
+		}
+
+		err := fn(hash, count)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// sha1Hex returns the uppercase hexadecimal SHA-1 of a password, as listed in the HIBP corpora.
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,94 @@
+package password_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/password"
+)
+
+func upperSha1(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeRanges writes the range files of the passwords, each seen count times.
+func writeRanges(t *testing.T, passwords map[string]int) string {
+	directory := t.TempDir()
+
+	for p, count := range passwords {
+		hash := upperSha1(p)
+		path := filepath.Join(directory, hash[:5]+".txt")
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		file.WriteString(hash[5:] + ":" + strconv.Itoa(count) + "\r\n")
+		file.Close()
+	}
+
+	return directory
+}
+
+func TestRangeChecker(t *testing.T) {
+	directory := writeRanges(t, map[string]int{"password123": 9, "rarely used": 1})
+
+	checker, err := password.NewRangeChecker(directory, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for p, expected := range map[string]bool{"password123": true, "rarely used": false, "never breached": false} {
+		breached, err := checker.IsBreached(p)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if breached != expected {
+			t.Errorf("expected breached %v for %q, got %v", expected, p, breached)
+		}
+	}
+}
+
+func TestReadDump(t *testing.T) {
+	directory := writeRanges(t, map[string]int{"password123": 9, "qwerty": 5})
+
+	file := filepath.Join(t.TempDir(), "dump.txt")
+	os.WriteFile(file, []byte(upperSha1("password123")+":9\n"+upperSha1("qwerty")+":5\n"), 0o644)
+
+	for _, path := range []string{directory, file} {
+		hashes := map[string]bool{}
+		err := password.ReadDump(path, 6, func(hash [sha1.Size]byte) error {
+			hashes[strings.ToUpper(hex.EncodeToString(hash[:]))] = true
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		if len(hashes) != 1 || !hashes[upperSha1("password123")] {
+			t.Errorf("expected only the hash of password123 in %s, got %v", path, hashes)
+		}
+	}
+}
+
+type breachedStub struct{}
+
+func (breachedStub) IsBreached(p string) (bool, error) {
+	return p == "correct horse battery staple", nil
+}
+
+func TestPolicy_Breached(t *testing.T) {
+	policy := password.DefaultPolicy()
+	policy.Breached = breachedStub{}
+
+	got := rules(policy.Check("correct horse battery staple", "test@test.com"))
+	if len(got) != 1 || got[0] != password.RULE_BREACHED {
+		t.Errorf("expected violation breached, got %v", got)
+	}
+}
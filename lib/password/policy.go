@@ -0,0 +1,196 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hhertout/twirp_auth/lib/config"
+)
+
+// Rules of the password policy, reported by the violations.
+const (
+	RULE_MIN_LENGTH     = "min_length"
+	RULE_MAX_LENGTH     = "max_length"
+	RULE_LOWERCASE      = "lowercase"
+	RULE_UPPERCASE      = "uppercase"
+	RULE_DIGIT          = "digit"
+	RULE_SYMBOL         = "symbol"
+	RULE_CONTAINS_EMAIL = "contains_email"
+	RULE_STRENGTH       = "strength"
+	RULE_BREACHED       = "breached"
+	// RULE_HISTORY is not checked by the policy but by the password history of the user, reported alike.
+	RULE_HISTORY = "history"
+)
+
+// Violation is a rule of the policy a password does not satisfy.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Policy describes the passwords accepted on registration and on password change.
+type Policy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MaxLength is the maximum number of characters, bounding the cost of the hashing.
+	MaxLength int
+	// RequireLowercase, RequireUppercase, RequireDigit and RequireSymbol require at least one character of the class.
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// ForbidEmail refuses the passwords containing the email of the user, or its local part.
+	ForbidEmail bool
+	// MinStrength is the minimum score of the strength estimator, from 0 to 4, 0 to disable the check.
+	MinStrength int
+	// Breached refuses the passwords appearing in the breach corpora, nil to disable the check.
+	Breached BreachCheckerInterface
+}
+
+// DefaultPolicy returns the policy used when none is configured: between 12 and 128 characters,
+// not containing the email, with a strength of at least 2. No character class is required.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:   12,
+		MaxLength:   128,
+		ForbidEmail: true,
+		MinStrength: 2,
+	}
+}
+
+// NewPolicyFromEnv reads the password policy from the "PASSWORD_MIN_LENGTH", "PASSWORD_MAX_LENGTH",
+// "PASSWORD_REQUIRE_LOWERCASE", "PASSWORD_REQUIRE_UPPERCASE", "PASSWORD_REQUIRE_DIGIT", "PASSWORD_REQUIRE_SYMBOL",
+// "PASSWORD_FORBID_EMAIL" and "PASSWORD_MIN_STRENGTH" environment variables, defaulting to DefaultPolicy.
+// The breach checker is read by NewBreachCheckerFromEnv.
+func NewPolicyFromEnv() (Policy, error) {
+	policy := DefaultPolicy()
+
+	var err error
+	if policy.MinLength, err = config.Int("PASSWORD_MIN_LENGTH", policy.MinLength); err != nil {
+		return Policy{}, err
+	}
+	if policy.MaxLength, err = config.Int("PASSWORD_MAX_LENGTH", policy.MaxLength); err != nil {
+		return Policy{}, err
+	}
+	if policy.RequireLowercase, err = config.Bool("PASSWORD_REQUIRE_LOWERCASE", policy.RequireLowercase); err != nil {
+		return Policy{}, err
+	}
+	if policy.RequireUppercase, err = config.Bool("PASSWORD_REQUIRE_UPPERCASE", policy.RequireUppercase); err != nil {
+		return Policy{}, err
+	}
+	if policy.RequireDigit, err = config.Bool("PASSWORD_REQUIRE_DIGIT", policy.RequireDigit); err != nil {
+		return Policy{}, err
+	}
+	if policy.RequireSymbol, err = config.Bool("PASSWORD_REQUIRE_SYMBOL", policy.RequireSymbol); err != nil {
+		return Policy{}, err
+	}
+	if policy.ForbidEmail, err = config.Bool("PASSWORD_FORBID_EMAIL", policy.ForbidEmail); err != nil {
+		return Policy{}, err
+	}
+	if policy.MinStrength, err = config.Int("PASSWORD_MIN_STRENGTH", policy.MinStrength); err != nil {
+		return Policy{}, err
+	}
+
+	if policy.MinLength < 1 {
+		return Policy{}, fmt.Errorf("env variable PASSWORD_MIN_LENGTH must be at least 1")
+	}
+	if policy.MaxLength < policy.MinLength {
+		return Policy{}, fmt.Errorf("env variable PASSWORD_MAX_LENGTH must be greater than PASSWORD_MIN_LENGTH")
+	}
+	if policy.MinStrength < 0 || policy.MinStrength > MAX_SCORE {
+		return Policy{}, fmt.Errorf("env variable PASSWORD_MIN_STRENGTH must be between 0 and %d", MAX_SCORE)
+	}
+
+	if policy.Breached, err = NewBreachCheckerFromEnv(); err != nil {
+		return Policy{}, err
+	}
+
+	return policy, nil
+}
+
+// Check returns every rule of the policy the password does not satisfy, none if the password is accepted.
+//
+// Parameters:
+// - password: the password chosen by the user.
+// - email: the email of the user, never allowed in its password when ForbidEmail is set.
+//
+// Returns:
+// - The violations, in the order of the rules, only the max_length one for an over-long password.
+// - An error if the breach corpora cannot be read.
+func (p Policy) Check(password string, email string) ([]Violation, error) {
+	violations := []Violation{}
+
+	// The other rules, the strength estimator and the breach lookup above all, are not run on an over-long password
+	if p.TooLong(password) {
+		return []Violation{{RULE_MAX_LENGTH, fmt.Sprintf("password must contain at most %d characters", p.MaxLength)}}, nil
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{RULE_MIN_LENGTH, fmt.Sprintf("password must contain at least %d characters", p.MinLength)})
+	}
+
+	if p.RequireLowercase && !strings.ContainsFunc(password, unicode.IsLower) {
+		violations = append(violations, Violation{RULE_LOWERCASE, "password must contain a lowercase letter"})
+	}
+	if p.RequireUppercase && !strings.ContainsFunc(password, unicode.IsUpper) {
+		violations = append(violations, Violation{RULE_UPPERCASE, "password must contain an uppercase letter"})
+	}
+	if p.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		violations = append(violations, Violation{RULE_DIGIT, "password must contain a digit"})
+	}
+	if p.RequireSymbol && !strings.ContainsFunc(password, isSymbol) {
+		violations = append(violations, Violation{RULE_SYMBOL, "password must contain a symbol"})
+	}
+
+	if p.ForbidEmail && containsEmail(password, email) {
+		violations = append(violations, Violation{RULE_CONTAINS_EMAIL, "password must not contain the email"})
+	}
+
+	if p.MinStrength > 0 {
+		if strength := Estimate(password, email); strength.Score < p.MinStrength {
+			violations = append(violations, Violation{RULE_STRENGTH, "password is too easy to guess"})
+		}
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			violations = append(violations, Violation{RULE_BREACHED, "password appears in a known data breach"})
+		}
+	}
+
+	return violations, nil
+}
+
+// TooLong reports whether the password exceeds the maximum length of the policy.
+// Over-long passwords are refused before being estimated, screened or hashed.
+func (p Policy) TooLong(password string) bool {
+	return p.MaxLength > 0 && utf8.RuneCountInString(password) > p.MaxLength
+}
+
+// isSymbol reports whether a character is neither a letter, a digit nor a space.
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+// containsEmail reports whether the password contains the email or its local part, ignoring the case.
+// Local parts shorter than 3 characters are ignored, they would reject too many passwords.
+func containsEmail(password string, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	password = strings.ToLower(password)
+	email = strings.ToLower(email)
+	if strings.Contains(password, email) {
+		return true
+	}
+
+	local, _, _ := strings.Cut(email, "@")
+	return utf8.RuneCountInString(local) >= 3 && strings.Contains(password, local)
+}
@@ -0,0 +1,145 @@
+package password_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/password"
+)
+
+func rules(violations []password.Violation, err error) []string {
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	result := []string{}
+	for _, v := range violations {
+		result = append(result, v.Rule)
+	}
+
+	return result
+}
+
+func TestPolicy_Accepted(t *testing.T) {
+	policy := password.DefaultPolicy()
+
+	violations, err := policy.Check("correct horse battery staple", "test@test.com")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("expected no violation, got %v", violations)
+	}
+}
+
+func TestPolicy_EveryViolation(t *testing.T) {
+	policy := password.Policy{
+		MinLength:        12,
+		MaxLength:        128,
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		ForbidEmail:      true,
+		MinStrength:      2,
+	}
+
+	got := rules(policy.Check("johnny", "johnny@test.com"))
+	expected := []string{
+		password.RULE_MIN_LENGTH,
+		password.RULE_UPPERCASE,
+		password.RULE_DIGIT,
+		password.RULE_SYMBOL,
+		password.RULE_CONTAINS_EMAIL,
+		password.RULE_STRENGTH,
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("expected violations %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected violation %v, got %v", expected[i], got[i])
+		}
+	}
+}
+
+func TestPolicy_MaxLength(t *testing.T) {
+	policy := password.DefaultPolicy()
+	policy.MaxLength = 16
+
+	got := rules(policy.Check("a very long passphrase nobody guesses", ""))
+	if len(got) != 1 || got[0] != password.RULE_MAX_LENGTH {
+		t.Errorf("expected violation max_length, got %v", got)
+	}
+}
+
+// countingChecker counts the breach lookups, every password is breached.
+type countingChecker struct {
+	lookups int
+}
+
+func (c *countingChecker) IsBreached(password string) (bool, error) {
+	c.lookups++
+	return true, nil
+}
+
+func TestPolicy_MaxLength_StopsCheck(t *testing.T) {
+	checker := &countingChecker{}
+	policy := password.DefaultPolicy()
+	policy.MaxLength = 16
+	policy.Breached = checker
+
+	got := rules(policy.Check("password password password", "password@test.com"))
+	if len(got) != 1 || got[0] != password.RULE_MAX_LENGTH {
+		t.Errorf("expected violation max_length only, got %v", got)
+	}
+	if checker.lookups != 0 {
+		t.Errorf("expected no breach lookup for an over-long password, got %d", checker.lookups)
+	}
+
+	if !policy.TooLong("password password password") || policy.TooLong("password") {
+		t.Errorf("expected only the password over 16 characters to be too long")
+	}
+}
+
+func TestPolicy_ContainsEmail(t *testing.T) {
+	policy := password.Policy{MinLength: 1, MaxLength: 128, ForbidEmail: true}
+
+	got := rules(policy.Check("my-JANE.DOE-secret", "jane.doe@test.com"))
+	if len(got) != 1 || got[0] != password.RULE_CONTAINS_EMAIL {
+		t.Errorf("expected violation contains_email, got %v", got)
+	}
+
+	// Short local parts are ignored
+	if got := rules(policy.Check("bobsled racing", "bo@test.com")); len(got) != 0 {
+		t.Errorf("expected no violation, got %v", got)
+	}
+}
+
+func TestNewPolicyFromEnv(t *testing.T) {
+	os.Setenv("PASSWORD_MIN_LENGTH", "8")
+	os.Setenv("PASSWORD_REQUIRE_DIGIT", "true")
+	defer os.Unsetenv("PASSWORD_MIN_LENGTH")
+	defer os.Unsetenv("PASSWORD_REQUIRE_DIGIT")
+
+	policy, err := password.NewPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if policy.MinLength != 8 || !policy.RequireDigit || policy.MaxLength != 128 {
+		t.Errorf("expected min length 8, a digit and max length 128, got %v", policy)
+	}
+}
+
+func TestNewPolicyFromEnv_Invalid(t *testing.T) {
+	os.Setenv("PASSWORD_MIN_LENGTH", "20")
+	os.Setenv("PASSWORD_MAX_LENGTH", "10")
+	defer os.Unsetenv("PASSWORD_MIN_LENGTH")
+	defer os.Unsetenv("PASSWORD_MAX_LENGTH")
+
+	if _, err := password.NewPolicyFromEnv(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
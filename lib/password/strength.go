@@ -0,0 +1,186 @@
+package password
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MAX_SCORE is the score of the strongest passwords.
+const MAX_SCORE = 4
+
+// Strength is the estimated strength of a password.
+type Strength struct {
+	// Score goes from 0, trivially guessable, to MAX_SCORE, very unlikely to be guessed.
+	Score int
+	// Entropy is the estimated number of guesses to find the password, in bits.
+	Entropy float64
+}
+
+// scoreThresholds are the entropies, in bits, from which each score above 0 is reached.
+var scoreThresholds = []float64{25, 40, 55, 70}
+
+// commonPasswords are words so frequent in the passwords that they are guessed first.
+var commonPasswords = []string{
+	"password", "passwd", "qwerty", "azerty", "letmein", "welcome", "admin", "administrator", "iloveyou",
+	"monkey", "dragon", "football", "baseball", "soccer", "sunshine", "princess", "master", "shadow",
+	"trustno1", "superman", "batman", "starwars", "whatever", "freedom", "hello", "secret", "changeme",
+	"login", "access", "love", "summer", "winter", "spring", "autumn", "michael", "jordan", "charlie",
+}
+
+// keyboardSequences are the keyboard rows and the ordered sequences, also guessed first, forwards or backwards.
+var keyboardSequences = []string{
+	"qwertyuiop", "asdfghjkl", "zxcvbnm", "azertyuiop", "qsdfghjklm", "wxcvbn",
+	"1234567890", "abcdefghijklmnopqrstuvwxyz",
+}
+
+// leetSpeak reverts the usual substitutions of letters, so that "p4ssw0rd" matches "password".
+// Each substitution replaces one character by one character, keeping the positions.
+var leetSpeak = strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// Bits of entropy of the predictable parts of a password.
+const (
+	dictionaryWordBits = 10
+	keyboardWalkBits   = 6
+	repeatBits         = 1
+)
+
+// Estimate estimates the strength of a password offline, in the manner of zxcvbn:
+// the common words, the keyboard walks, the repeated and the sequential characters are cheap to guess,
+// the remaining characters are worth the size of the alphabet they are drawn from.
+//
+// Parameters:
+// - password: the password to estimate.
+// - userInputs: the values known about the user, such as the email, as cheap to guess as common words.
+//
+// Returns:
+// - The estimated strength of the password.
+func Estimate(password string, userInputs ...string) Strength {
+	if password == "" {
+		return Strength{}
+	}
+
+	runes := []rune(strings.ToLower(password))
+	unleeted := []rune(leetSpeak.Replace(string(runes)))
+	covered := make([]bool, len(runes))
+
+	entropy := 0.0
+	for _, word := range slices.Concat(commonPasswords, userWords(userInputs)) {
+		entropy += coverMatches(unleeted, covered, []rune(word), dictionaryWordBits)
+	}
+	for _, sequence := range keyboardSequences {
+		entropy += coverWalks(runes, covered, []rune(sequence))
+	}
+
+	bitsPerCharacter := math.Log2(float64(alphabetSize(password)))
+	for i, r := range runes {
+		if covered[i] {
+			continue
+		}
+
+		// A character repeating or following the previous one is almost free to guess
+		if i > 0 && (r == runes[i-1] || r == runes[i-1]+1 || r == runes[i-1]-1) {
+			entropy += repeatBits
+			continue
+		}
+
+		entropy += bitsPerCharacter
+	}
+
+	score := 0
+	for _, threshold := range scoreThresholds {
+		if entropy >= threshold {
+			score++
+		}
+	}
+
+	return Strength{Score: score, Entropy: entropy}
+}
+
+// alphabetSize returns the number of characters of the classes the password draws from.
+func alphabetSize(password string) int {
+	size := 0
+	if strings.ContainsFunc(password, unicode.IsLower) {
+		size += 26
+	}
+	if strings.ContainsFunc(password, unicode.IsUpper) {
+		size += 26
+	}
+	if strings.ContainsFunc(password, unicode.IsDigit) {
+		size += 10
+	}
+	if strings.ContainsFunc(password, isSymbol) {
+		size += 33
+	}
+	if strings.ContainsFunc(password, func(r rune) bool { return r > unicode.MaxASCII }) {
+		size += 100
+	}
+
+	return max(size, 1)
+}
+
+// userWords splits the user inputs into the words worth matching, of 3 characters at least.
+func userWords(userInputs []string) []string {
+	words := []string{}
+	for _, input := range userInputs {
+		fields := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, field := range fields {
+			if len([]rune(field)) >= 3 {
+				words = append(words, field)
+			}
+		}
+	}
+
+	return words
+}
+
+// coverMatches marks the occurrences of a word not covered yet, and returns the bits they are worth.
+func coverMatches(runes []rune, covered []bool, word []rune, bits float64) float64 {
+	entropy := 0.0
+	for i := 0; i+len(word) <= len(runes); i++ {
+		if !matchesAt(runes, covered, word, i) {
+			continue
+		}
+
+		for j := range word {
+			covered[i+j] = true
+		}
+		entropy += bits
+		i += len(word) - 1
+	}
+
+	return entropy
+}
+
+// coverWalks marks the parts of the password of 4 characters or more walking along a sequence, forwards or backwards.
+func coverWalks(runes []rune, covered []bool, sequence []rune) float64 {
+	reversed := make([]rune, len(sequence))
+	for i, r := range sequence {
+		reversed[len(sequence)-1-i] = r
+	}
+
+	entropy := 0.0
+	for _, s := range [][]rune{sequence, reversed} {
+		for length := len(s); length >= 4; length-- {
+			for start := 0; start+length <= len(s); start++ {
+				entropy += coverMatches(runes, covered, s[start:start+length], keyboardWalkBits)
+			}
+		}
+	}
+
+	return entropy
+}
+
+// matchesAt reports whether the word appears at the position, on characters not covered yet.
+func matchesAt(runes []rune, covered []bool, word []rune, position int) bool {
+	for j, r := range word {
+		if covered[position+j] || runes[position+j] != r {
+			return false
+		}
+	}
+
+	return true
+}
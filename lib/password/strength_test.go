@@ -0,0 +1,32 @@
+package password_test
+
+import (
+	"testing"
+
+	"github.com/hhertout/twirp_auth/lib/password"
+)
+
+func TestEstimate_Weak(t *testing.T) {
+	for _, p := range []string{"", "password", "P4ssw0rd", "aaaaaaaaaaaa", "abcdefghijkl", "qwertyuiop12"} {
+		if strength := password.Estimate(p); strength.Score > 1 {
+			t.Errorf("expected %q to be weak, got score %d", p, strength.Score)
+		}
+	}
+}
+
+func TestEstimate_Strong(t *testing.T) {
+	for _, p := range []string{"correcthorsebatterystaple", "X9#vL2!qR7@m"} {
+		if strength := password.Estimate(p); strength.Score < 3 {
+			t.Errorf("expected %q to be strong, got score %d", p, strength.Score)
+		}
+	}
+}
+
+func TestEstimate_UserInputs(t *testing.T) {
+	without := password.Estimate("johnsmith1234")
+	with := password.Estimate("johnsmith1234", "john.smith@test.com")
+
+	if with.Entropy >= without.Entropy {
+		t.Errorf("expected the user inputs to lower the entropy, got %v with and %v without", with.Entropy, without.Entropy)
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter counts the events of each key, such as an email or a client IP, over fixed windows
+// and refuses them once the limit of the window is reached.
+// The counters live in memory: each instance of the service enforces the limit on its own,
+// the ended windows are removed by Prune.
+type Limiter struct {
+	// Limit is the number of events allowed per key within a window, no limit when 0.
+	Limit  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window is the current window of a key.
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter creates a limiter allowing limit events per key within each window.
+// Returns a pointer to the newly created Limiter.
+func NewLimiter(limit int, w time.Duration) *Limiter {
+	return &Limiter{
+		Limit:   limit,
+		Window:  w,
+		windows: map[string]*window{},
+	}
+}
+
+// Allow records an event of the key.
+// Returns false if the limit of the current window of the key is already reached, the event is then not counted.
+func (l *Limiter) Allow(key string) bool {
+	if l.Limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	current, ok := l.windows[key]
+	if !ok || now.Sub(current.start) >= l.Window {
+		l.windows[key] = &window{start: now, count: 1}
+		return true
+	}
+
+	if current.count >= l.Limit {
+		return false
+	}
+
+	current.count++
+	return true
+}
+
+// Prune removes the windows that have ended.
+// Returns the number of removed windows.
+func (l *Limiter) Prune() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	pruned := 0
+	for key, current := range l.windows {
+		if now.Sub(current.start) >= l.Window {
+			delete(l.windows, key)
+			pruned++
+		}
+	}
+
+	return pruned
+}
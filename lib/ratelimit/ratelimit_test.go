@@ -0,0 +1,64 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hhertout/twirp_auth/lib/ratelimit"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := ratelimit.NewLimiter(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("user@example.com") {
+			t.Errorf("expected event %d to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("user@example.com") {
+		t.Errorf("expected the event over the limit to be refused")
+	}
+
+	if !limiter.Allow("other@example.com") {
+		t.Errorf("expected the events of another key to be counted apart")
+	}
+}
+
+func TestLimiter_WindowEnds(t *testing.T) {
+	limiter := ratelimit.NewLimiter(1, 20*time.Millisecond)
+
+	limiter.Allow("127.0.0.1")
+	if limiter.Allow("127.0.0.1") {
+		t.Errorf("expected the event over the limit to be refused")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !limiter.Allow("127.0.0.1") {
+		t.Errorf("expected the event of a new window to be allowed")
+	}
+}
+
+func TestLimiter_NoLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(0, time.Hour)
+
+	for i := 0; i < 100; i++ {
+		if !limiter.Allow("127.0.0.1") {
+			t.Fatalf("expected every event to be allowed without limit")
+		}
+	}
+}
+
+func TestLimiter_Prune(t *testing.T) {
+	limiter := ratelimit.NewLimiter(1, 20*time.Millisecond)
+
+	limiter.Allow("127.0.0.1")
+	limiter.Allow("127.0.0.2")
+	if pruned := limiter.Prune(); pruned != 0 {
+		t.Errorf("expected the current windows to be kept, got %d pruned", pruned)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if pruned := limiter.Prune(); pruned != 2 {
+		t.Errorf("expected 2 pruned windows, got %d", pruned)
+	}
+}
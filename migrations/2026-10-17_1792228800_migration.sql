@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS signing_key (
+    kid VARCHAR(128) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    material BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deactivated_at TIMESTAMPTZ
+);
@@ -0,0 +1 @@
+ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS remember_me BOOLEAN NOT NULL DEFAULT FALSE;
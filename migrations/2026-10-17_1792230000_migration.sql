@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS opaque_token (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    jti VARCHAR(64) NOT NULL,
+    claims JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+--
+
+CREATE INDEX IF NOT EXISTS idx_opaque_token_expires_at ON opaque_token (expires_at);
@@ -0,0 +1 @@
+ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS dpop_jkt VARCHAR(64);
@@ -0,0 +1,48 @@
+ALTER TABLE IF EXISTS "user"
+ADD IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+--
+
+CREATE OR REPLACE FUNCTION f_bump_token_version()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF New.password IS DISTINCT FROM Old.password
+        OR New.role IS DISTINCT FROM Old.role
+        OR New.deleted_at IS DISTINCT FROM Old.deleted_at THEN
+        New.token_version = Old.token_version + 1;
+    END IF;
+    RETURN New;
+END;
+$$ language 'plpgsql';
+
+--
+
+CREATE OR REPLACE FUNCTION f_revoke_refresh_tokens()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF New.token_version IS DISTINCT FROM Old.token_version THEN
+        UPDATE refresh_token SET revoked_at = NOW() WHERE user_id = New.id AND revoked_at IS NULL;
+    END IF;
+    RETURN New;
+END;
+$$ language 'plpgsql';
+
+--
+
+CREATE
+OR
+REPLACE
+    TRIGGER t_bump_token_version BEFORE
+UPDATE ON "user" FOR EACH ROW
+EXECUTE PROCEDURE f_bump_token_version ();
+
+--
+
+CREATE
+OR
+REPLACE
+    TRIGGER t_revoke_refresh_tokens AFTER
+UPDATE ON "user" FOR EACH ROW
+EXECUTE PROCEDURE f_revoke_refresh_tokens ();
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS session (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    client_ip VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+--
+
+CREATE INDEX IF NOT EXISTS idx_session_user_id ON session (user_id);
+
+--
+
+ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES session (id) ON DELETE CASCADE;
+
+--
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_session_id ON refresh_token (session_id);
@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION f_bump_token_version()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (New.password IS DISTINCT FROM Old.password
+            AND COALESCE(current_setting('app.password_rehash', true), '') <> 'on')
+        OR New.role IS DISTINCT FROM Old.role
+        OR New.deleted_at IS DISTINCT FROM Old.deleted_at THEN
+        New.token_version = Old.token_version + 1;
+    END IF;
+    RETURN New;
+END;
+$$ language 'plpgsql';
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+--
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history (user_id, created_at DESC);
+
+--
+
+CREATE OR REPLACE FUNCTION f_record_password_history()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF New.password IS DISTINCT FROM Old.password
+        AND COALESCE(current_setting('app.password_rehash', true), '') <> 'on' THEN
+        INSERT INTO password_history (user_id, password) VALUES (Old.id, Old.password);
+    END IF;
+    RETURN New;
+END;
+$$ language 'plpgsql';
+
+--
+
+CREATE
+OR
+REPLACE
+    TRIGGER t_record_password_history AFTER
+UPDATE ON "user" FOR EACH ROW
+EXECUTE PROCEDURE f_record_password_history ();
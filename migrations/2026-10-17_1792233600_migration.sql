@@ -0,0 +1,27 @@
+ALTER TABLE IF EXISTS "user"
+ADD IF NOT EXISTS password_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+ADD IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+
+--
+
+CREATE OR REPLACE FUNCTION f_set_password_changed()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF New.password IS DISTINCT FROM Old.password
+        AND COALESCE(current_setting('app.password_rehash', true), '') <> 'on' THEN
+        New.password_changed_at = NOW();
+        New.must_change_password = FALSE;
+    END IF;
+    RETURN New;
+END;
+$$ language 'plpgsql';
+
+--
+
+CREATE
+OR
+REPLACE
+    TRIGGER t_set_password_changed BEFORE
+UPDATE ON "user" FOR EACH ROW
+EXECUTE PROCEDURE f_set_password_changed ();
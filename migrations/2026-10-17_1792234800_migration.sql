@@ -0,0 +1,12 @@
+ALTER TABLE IF EXISTS signing_key
+ADD IF NOT EXISTS activates_at TIMESTAMPTZ;
+
+--
+
+UPDATE signing_key SET activates_at = created_at WHERE activates_at IS NULL;
+
+--
+
+ALTER TABLE IF EXISTS signing_key
+ALTER COLUMN activates_at SET DEFAULT NOW(),
+ALTER COLUMN activates_at SET NOT NULL;
@@ -168,6 +168,15 @@ func (m *Migration) GetMigrationFiles(basePath string) ([]string, error) {
 
 	sort.Strings(res)
 
+	// init.sql creates the base schema, it must run before the generated migrations
+	// even though their date prefix sorts first.
+	for i, f := range res {
+		if f == "init.sql" {
+			res = append([]string{f}, append(res[:i:i], res[i+1:]...)...)
+			break
+		}
+	}
+
 	return res, nil
 }
 
@@ -3,84 +3,185 @@ package auth
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/hhertout/twirp_auth/internal/hooks"
 	"github.com/hhertout/twirp_auth/lib/crypto"
 	"github.com/hhertout/twirp_auth/pkg/auth/role"
 	"github.com/hhertout/twirp_auth/pkg/dto"
+	"github.com/twitchtv/twirp"
+	"go.uber.org/zap"
 )
 
+// ErrActingToken is returned when a token carrying an act claim, issued by an impersonation or a token exchange,
+// is used on a RPC that only the user can call.
+var ErrActingToken = errors.New("token acting on behalf of the user is not allowed")
+
+// ErrPasswordChangeRequired is returned when a token restricted to the change of the password
+// is used on another RPC than UpdatePassword.
+var ErrPasswordChangeRequired = errors.New("password must be changed")
+
+// PASSWORD_CHANGE_METHOD is the only RPC accepting the tokens restricted to the change of the password.
+const PASSWORD_CHANGE_METHOD = "UpdatePassword"
+
 type AuthManager struct {
 	Dal        AuthDataLayerInterface
 	JWTManager crypto.JWTServiceInterface
+	Logger     *zap.Logger
 }
 
-func NewAuthManager(r AuthDataLayerInterface) *AuthManager {
+// NewAuthManager creates a new instance of AuthManager.
+// The JWT service is used to verify the tokens, it should consult the token denylist
+// so that revoked tokens are refused. The logger flags the calls made on behalf of a user.
+func NewAuthManager(r AuthDataLayerInterface, j crypto.JWTServiceInterface, l *zap.Logger) *AuthManager {
 	return &AuthManager{
 		Dal:        r,
-		JWTManager: crypto.NewJWTService(),
+		JWTManager: j,
+		Logger:     l,
 	}
 }
 
 func (am *AuthManager) RestrictAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error) {
-	token, _ := ctx.Value(hooks.ServerContextKey("Authorization")).(string)
-	if token == "" {
-		return dto.User{}, errors.New("token is missing")
+	user, _, err := am.Authenticate(ctx)
+	if err != nil {
+		return dto.User{}, err
 	}
 
-	isValid, claims, err := am.JWTManager.Verify(token)
+	for _, r := range user.Role {
+		if role.Contains(roles, r) {
+			return dto.User{}, errors.New("user does not have the required role")
+		}
+	}
+
+	return user, nil
+}
+
+func (am *AuthManager) AllowAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error) {
+	user, claims, err := am.Authenticate(ctx)
 	if err != nil {
 		return dto.User{}, err
 	}
-	if !isValid {
-		return dto.User{}, errors.New("invalid token")
+
+	// The admin RPCs are never called on behalf of an admin, even with a token carrying the admin role
+	if claims.Act != nil && role.Contains(roles, string(role.ROLE_ADMIN)) {
+		return dto.User{}, ErrActingToken
 	}
 
-	user, err := am.Dal.FindOneByEmail(claims.Issuer)
+	return allowRoles(user, roles)
+}
+
+func (am *AuthManager) AllowDirectAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error) {
+	user, claims, err := am.Authenticate(ctx)
 	if err != nil {
 		return dto.User{}, err
 	}
 
-	if len(roles) > 0 {
-		for _, r := range user.Role {
-			if role.Contains(roles, r) {
-				return dto.User{}, errors.New("user does not have the required role")
-			}
+	if claims.Act != nil {
+		return dto.User{}, ErrActingToken
+	}
+
+	return allowRoles(user, roles)
+}
+
+// actingRoles returns the roles of the user granted to a token acting on its behalf.
+func actingRoles(userRoles []string, tokenRoles []string) []string {
+	roles := []string{}
+	for _, r := range userRoles {
+		if slices.Contains(tokenRoles, r) {
+			roles = append(roles, r)
 		}
+	}
 
+	return roles
+}
+
+// allowRoles returns the user if it has one of the roles, any user when roles is empty.
+func allowRoles(user dto.User, roles []role.ROLE) (dto.User, error) {
+	// Without required roles, any authenticated user is allowed
+	if len(roles) == 0 {
 		return user, nil
-	} else {
-		return user, nil
 	}
+
+	for _, r := range user.Role {
+		if role.Contains(roles, r) {
+			return user, nil
+		}
+	}
+
+	return dto.User{}, errors.New("user does not have the required role")
 }
 
-func (am *AuthManager) AllowAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error) {
+// Authenticate verifies the access token of the context and loads its user.
+// Tokens issued before the last bump of the token version of the user are rejected,
+// as well as the tokens of a revoked session.
+// A token bound to a DPoP key is only accepted with the DPoP scheme and a proof of that key,
+// validated beforehand by the WithHeaders middleware.
+// The tokens restricted to the change of the password are only accepted by UpdatePassword.
+// The calls made with a token acting on behalf of the user are logged along with the actor,
+// and only granted the roles of the token that the user still has.
+func (am *AuthManager) Authenticate(ctx context.Context) (dto.User, crypto.Claims, error) {
 	token, _ := ctx.Value(hooks.ServerContextKey("Authorization")).(string)
 	if token == "" {
-		return dto.User{}, errors.New("token is missing")
+		return dto.User{}, crypto.Claims{}, errors.New("token is missing")
 	}
 
 	isValid, claims, err := am.JWTManager.Verify(token)
-
 	if err != nil {
-		return dto.User{}, err
+		return dto.User{}, crypto.Claims{}, err
 	}
 	if !isValid {
-		return dto.User{}, errors.New("invalid token")
+		return dto.User{}, crypto.Claims{}, errors.New("invalid token")
+	}
+
+	if jkt := claims.BoundKey(); jkt != "" {
+		scheme, _ := ctx.Value(hooks.ServerContextKey("authorization-scheme")).(string)
+		proofKey, _ := ctx.Value(hooks.ServerContextKey("dpop-jkt")).(string)
+		if scheme != "DPoP" || proofKey != jkt {
+			return dto.User{}, crypto.Claims{}, errors.New("token requires a DPoP proof of its key")
+		}
 	}
 
-	user, err := am.Dal.FindOneByEmail(claims.Issuer)
+	user, err := am.Dal.FindOneByUuid(claims.Subject)
 	if err != nil {
-		return dto.User{}, err
+		return dto.User{}, crypto.Claims{}, err
+	}
+	if user.Id == "" {
+		return dto.User{}, crypto.Claims{}, errors.New("user not found")
+	}
+
+	// The token version is bumped when every session of the user must end
+	if err := claims.CheckVersion(user.TokenVersion); err != nil {
+		return dto.User{}, crypto.Claims{}, err
 	}
 
-	if len(roles) > 0 {
-		for _, r := range user.Role {
-			if role.Contains(roles, r) {
-				return user, nil
-			}
+	if claims.SessionId != "" {
+		active, err := am.Dal.IsSessionActive(claims.SessionId)
+		if err != nil {
+			return dto.User{}, crypto.Claims{}, err
+		}
+		if !active {
+			return dto.User{}, crypto.Claims{}, errors.New("session has been revoked")
 		}
 	}
 
-	return dto.User{}, errors.New("user does not have the required role")
+	method, _ := twirp.MethodName(ctx)
+	if claims.PasswordChangeRequired() && method != PASSWORD_CHANGE_METHOD {
+		return dto.User{}, crypto.Claims{}, ErrPasswordChangeRequired
+	}
+
+	// A token exchange narrows the roles of the token, the roles of the user would grant it more
+	if claims.Act != nil {
+		user.Role = actingRoles(user.Role, claims.Roles)
+	}
+
+	if claims.Act != nil && am.Logger != nil {
+		am.Logger.Warn("Request made on behalf of a user",
+			zap.String("method", method),
+			zap.String("subject", claims.Subject),
+			zap.String("actor", claims.Act.Subject),
+			zap.String("actor_email", claims.Act.Email),
+		)
+	}
+
+	return user, claims, nil
 }
@@ -0,0 +1,143 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hhertout/twirp_auth/internal/hooks"
+	"github.com/hhertout/twirp_auth/lib/crypto"
+	"github.com/hhertout/twirp_auth/pkg/auth"
+	"github.com/hhertout/twirp_auth/pkg/auth/role"
+	"github.com/hhertout/twirp_auth/pkg/dto"
+)
+
+// fakeDal serves the users from memory, the banned and deleted users are not found, as in the database.
+type fakeDal struct {
+	users map[string]dto.User
+}
+
+func (d fakeDal) FindOneByUuid(uuid string) (dto.User, error) {
+	return d.users[uuid], nil
+}
+
+func (d fakeDal) IsSessionActive(sessionId string) (bool, error) {
+	return true, nil
+}
+
+func newAdmin() dto.User {
+	return dto.User{Id: "1", Uuuid: "admin-uuid", Email: "admin@example.com", Role: []string{"USER", "ADMIN"}, TokenVersion: 1}
+}
+
+func newAuthManager(t *testing.T, users ...dto.User) (*auth.AuthManager, crypto.JWTServiceInterface) {
+	os.Setenv("JWT_SECRET", "test_secret")
+	t.Cleanup(func() { os.Unsetenv("JWT_SECRET") })
+
+	dal := fakeDal{users: map[string]dto.User{}}
+	for _, user := range users {
+		dal.users[user.Uuuid] = user
+	}
+
+	jwtService := crypto.NewJWTService()
+	return auth.NewAuthManager(dal, jwtService, nil), jwtService
+}
+
+func withToken(token string) context.Context {
+	return context.WithValue(context.Background(), hooks.ServerContextKey("Authorization"), token)
+}
+
+func TestAllowAccessWithRole(t *testing.T) {
+	admin := newAdmin()
+	am, jwtService := newAuthManager(t, admin)
+
+	claims := crypto.NewClaims(admin.Uuuid, admin.Email, admin.Role)
+	claims.TokenVersion = admin.TokenVersion
+	token, _ := jwtService.Generate(claims)
+
+	user, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{role.ROLE_ADMIN})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if user.Uuuid != admin.Uuuid {
+		t.Errorf("expected user %s, got %s", admin.Uuuid, user.Uuuid)
+	}
+}
+
+func TestAllowAccessWithRole_ActingTokenNarrowedRoles(t *testing.T) {
+	admin := newAdmin()
+	am, jwtService := newAuthManager(t, admin)
+
+	// A token exchanged with the roles narrowed to nothing
+	claims := crypto.NewClaims(admin.Uuuid, admin.Email, []string{})
+	claims.TokenVersion = admin.TokenVersion
+	claims.Act = &crypto.Actor{Subject: "billing"}
+	token, _ := jwtService.Generate(claims)
+
+	if _, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{role.ROLE_ADMIN}); err == nil {
+		t.Errorf("expected the acting token to be refused the roles it does not carry")
+	}
+	if _, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{}); err != nil {
+		t.Errorf("expected the acting token to be authenticated, got %v", err)
+	}
+}
+
+func TestAllowAccessWithRole_ActingTokenRoleRemovedFromUser(t *testing.T) {
+	admin := newAdmin()
+	demoted := admin
+	demoted.Role = []string{"USER"}
+	am, jwtService := newAuthManager(t, demoted)
+
+	claims := crypto.NewClaims(admin.Uuuid, admin.Email, admin.Role)
+	claims.TokenVersion = admin.TokenVersion
+	claims.Act = &crypto.Actor{Subject: "billing"}
+	token, _ := jwtService.Generate(claims)
+
+	if _, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{role.ROLE_ADMIN}); err == nil {
+		t.Errorf("expected the acting token to be refused a role the user no longer has")
+	}
+}
+
+func TestAllowAccessWithRole_ActingTokenRefusedOnAdminRPC(t *testing.T) {
+	admin := newAdmin()
+	am, jwtService := newAuthManager(t, admin)
+
+	// A token exchanged by a client for an admin, still carrying the admin role
+	claims := crypto.NewClaims(admin.Uuuid, admin.Email, admin.Role)
+	claims.TokenVersion = admin.TokenVersion
+	claims.Act = &crypto.Actor{Subject: "billing"}
+	token, _ := jwtService.Generate(claims)
+
+	if _, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{role.ROLE_ADMIN}); !errors.Is(err, auth.ErrActingToken) {
+		t.Errorf("expected ErrActingToken, got %v", err)
+	}
+	if _, err := am.AllowAccessWithRole(withToken(token), []role.ROLE{role.ROLE_USER}); err != nil {
+		t.Errorf("expected the acting token to be allowed on the user RPCs, got %v", err)
+	}
+}
+
+func TestAuthenticate_BannedUser(t *testing.T) {
+	user := dto.User{Id: "2", Uuuid: "user-uuid", Email: "user@example.com", Role: []string{"USER"}, TokenVersion: 1}
+	am, jwtService := newAuthManager(t, user)
+
+	claims := crypto.NewClaims(user.Uuuid, user.Email, user.Role)
+	claims.TokenVersion = user.TokenVersion
+	token, _ := jwtService.Generate(claims)
+
+	if _, _, err := am.Authenticate(withToken(token)); err != nil {
+		t.Errorf("expected the token to be accepted before the ban, got %v", err)
+	}
+
+	// The ban soft deletes the user and bumps its token version
+	banned, _ := newAuthManager(t)
+	if _, _, err := banned.Authenticate(withToken(token)); err == nil {
+		t.Errorf("expected the token of a banned user to be refused")
+	}
+
+	// Once unbanned, the tokens issued before the ban stay refused
+	user.TokenVersion++
+	unbanned, _ := newAuthManager(t, user)
+	if _, _, err := unbanned.Authenticate(withToken(token)); err == nil {
+		t.Errorf("expected the token issued before the ban to be refused")
+	}
+}
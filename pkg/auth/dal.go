@@ -6,13 +6,18 @@ import (
 )
 
 type AuthDataLayer struct {
-	repository *repository.UserRepository
+	repository        *repository.UserRepository
+	sessionRepository *repository.SessionRepository
 }
 
-func NewAuthDataLayer(r *repository.UserRepository) *AuthDataLayer {
-	return &AuthDataLayer{r}
+func NewAuthDataLayer(r *repository.UserRepository, s *repository.SessionRepository) *AuthDataLayer {
+	return &AuthDataLayer{r, s}
 }
 
-func (dl *AuthDataLayer) FindOneByEmail(email string) (dto.User, error) {
-	return dl.repository.FindOneByEmail(email)
+func (dl *AuthDataLayer) FindOneByUuid(uuid string) (dto.User, error) {
+	return dl.repository.FindOneByUuid(uuid)
+}
+
+func (dl *AuthDataLayer) IsSessionActive(sessionId string) (bool, error) {
+	return dl.sessionRepository.IsActive(sessionId)
 }
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 
+	"github.com/hhertout/twirp_auth/lib/crypto"
 	"github.com/hhertout/twirp_auth/pkg/auth/role"
 	"github.com/hhertout/twirp_auth/pkg/dto"
 )
@@ -26,6 +27,8 @@ type AuthManagerInterface interface {
 
 	// AllowAccessWithRole allows access to a user based on their role.
 	// It verifies the JWT token from the context and checks if the user has one of the required roles.
+	// An empty slice of roles allows any authenticated user.
+	// When the admin role is required, the tokens acting on behalf of the user are refused with ErrActingToken.
 	// If the token is missing, invalid, or the user does not have the required role, it returns an error.
 	// It return an error if the user does not have the role specified in the slice, or the user otherwise.
 	//
@@ -37,8 +40,35 @@ type AuthManagerInterface interface {
 	// - The user if they have the required role.
 	// - An error if the token is missing, invalid, or the user does not have the required role.
 	AllowAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error)
+
+	// AllowDirectAccessWithRole allows access to a user based on their role, like AllowAccessWithRole,
+	// but only with a token of the user itself: the tokens acting on behalf of the user are refused.
+	// It guards the sensitive RPCs, such as the update of the password or the deletion of an account.
+	//
+	// Parameters:
+	// - ctx: the context containing the JWT token.
+	// - roles: a slice of roles that are required for access.
+	//
+	// Returns:
+	// - The user if they have the required role.
+	// - ErrActingToken if the token carries an act claim, or an error if the token is missing, invalid,
+	//   or the user does not have the required role.
+	AllowDirectAccessWithRole(ctx context.Context, roles []role.ROLE) (dto.User, error)
+
+	// Authenticate verifies the JWT token from the context and returns its user along with its claims.
+	// It is used when the claims of the token matter, such as its session.
+	// A token acting on behalf of the user is only granted the roles of the token that the user still has.
+	//
+	// Parameters:
+	// - ctx: the context containing the JWT token.
+	//
+	// Returns:
+	// - The user and the claims of the token.
+	// - An error if the token is missing, invalid, or its session has been revoked.
+	Authenticate(ctx context.Context) (dto.User, crypto.Claims, error)
 }
 
 type AuthDataLayerInterface interface {
-	FindOneByEmail(email string) (dto.User, error)
+	FindOneByUuid(uuid string) (dto.User, error)
+	IsSessionActive(sessionId string) (bool, error)
 }
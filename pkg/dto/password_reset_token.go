@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type PasswordResetToken struct {
+	Id        string     `db:"id"`
+	UserId    string     `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type RefreshToken struct {
+	Id        string     `db:"id"`
+	UserId    string     `db:"user_id"`
+	FamilyId  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	// RememberMe is set when the family was opened by a login asking to be remembered.
+	RememberMe bool `db:"remember_me"`
+	// DPoPJkt is the thumbprint of the DPoP key the family is bound to, nil when it is not bound.
+	DPoPJkt *string `db:"dpop_jkt"`
+	// SessionId is the session the family belongs to, nil for the families opened before sessions were recorded.
+	SessionId *string `db:"session_id"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// Session is a login of a user on a device, it lasts as long as its refresh token family.
+type Session struct {
+	Id         string     `db:"id"`
+	UserId     string     `db:"user_id"`
+	UserAgent  string     `db:"user_agent"`
+	ClientIp   string     `db:"client_ip"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
@@ -1,11 +1,19 @@
 package dto
 
+import "time"
+
 type User struct {
 	Id       string   `db:"id"`
 	Uuuid    string   `db:"uuid"`
 	Email    string   `db:"email"`
 	Password string   `db:"password"`
 	Role     []string `db:"role"`
+	// TokenVersion is embedded in the issued tokens, bumping it invalidates every outstanding token.
+	TokenVersion int `db:"token_version"`
+	// PasswordChangedAt is the date the password was last chosen by the user.
+	PasswordChangedAt time.Time `db:"password_changed_at"`
+	// MustChangePassword is set by an admin to force the user to change its password on its next login.
+	MustChangePassword bool `db:"must_change_password"`
 }
 
 type CompleteUser struct {
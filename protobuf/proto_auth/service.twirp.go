@@ -35,6 +35,14 @@ type AuthenticationService interface {
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 
 	CheckToken(context.Context, *CheckTokenRequest) (*CheckTokenResponse, error)
+
+	Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error)
+
+	Logout(context.Context, *LogoutRequest) (*LogoutResponse, error)
+
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+
+	RotateSigningKey(context.Context, *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error)
 }
 
 // =====================================
@@ -43,7 +51,7 @@ type AuthenticationService interface {
 
 type authenticationServiceProtobufClient struct {
 	client      HTTPClient
-	urls        [2]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -71,9 +79,13 @@ func NewAuthenticationServiceProtobufClient(baseURL string, client HTTPClient, o
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "auth", "AuthenticationService")
-	urls := [2]string{
+	urls := [6]string{
 		serviceURL + "Login",
 		serviceURL + "CheckToken",
+		serviceURL + "Refresh",
+		serviceURL + "Logout",
+		serviceURL + "RevokeToken",
+		serviceURL + "RotateSigningKey",
 	}
 
 	return &authenticationServiceProtobufClient{
@@ -176,13 +188,197 @@ func (c *authenticationServiceProtobufClient) callCheckToken(ctx context.Context
 	return out, nil
 }
 
+func (c *authenticationServiceProtobufClient) Refresh(ctx context.Context, in *RefreshRequest) (*RefreshResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "Refresh")
+	caller := c.callRefresh
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RefreshRequest) (*RefreshResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshRequest) when calling interceptor")
+					}
+					return c.callRefresh(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceProtobufClient) callRefresh(ctx context.Context, in *RefreshRequest) (*RefreshResponse, error) {
+	out := new(RefreshResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceProtobufClient) Logout(ctx context.Context, in *LogoutRequest) (*LogoutResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "Logout")
+	caller := c.callLogout
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*LogoutRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*LogoutRequest) when calling interceptor")
+					}
+					return c.callLogout(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*LogoutResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*LogoutResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceProtobufClient) callLogout(ctx context.Context, in *LogoutRequest) (*LogoutResponse, error) {
+	out := new(LogoutResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceProtobufClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeToken")
+	caller := c.callRevokeToken
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeTokenRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeTokenRequest) when calling interceptor")
+					}
+					return c.callRevokeToken(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeTokenResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeTokenResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceProtobufClient) callRevokeToken(ctx context.Context, in *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	out := new(RevokeTokenResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceProtobufClient) RotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "RotateSigningKey")
+	caller := c.callRotateSigningKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RotateSigningKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RotateSigningKeyRequest) when calling interceptor")
+					}
+					return c.callRotateSigningKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RotateSigningKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RotateSigningKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceProtobufClient) callRotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+	out := new(RotateSigningKeyResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // =================================
 // AuthenticationService JSON Client
 // =================================
 
 type authenticationServiceJSONClient struct {
 	client      HTTPClient
-	urls        [2]string
+	urls        [6]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -210,9 +406,13 @@ func NewAuthenticationServiceJSONClient(baseURL string, client HTTPClient, opts
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "auth", "AuthenticationService")
-	urls := [2]string{
+	urls := [6]string{
 		serviceURL + "Login",
 		serviceURL + "CheckToken",
+		serviceURL + "Refresh",
+		serviceURL + "Logout",
+		serviceURL + "RevokeToken",
+		serviceURL + "RotateSigningKey",
 	}
 
 	return &authenticationServiceJSONClient{
@@ -264,168 +464,1084 @@ func (c *authenticationServiceJSONClient) callLogin(ctx context.Context, in *Log
 		return nil, err
 	}
 
-	callClientResponseReceived(ctx, c.opts.Hooks)
-
-	return out, nil
-}
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceJSONClient) CheckToken(ctx context.Context, in *CheckTokenRequest) (*CheckTokenResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
+	caller := c.callCheckToken
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CheckTokenRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+					}
+					return c.callCheckToken(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CheckTokenResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceJSONClient) callCheckToken(ctx context.Context, in *CheckTokenRequest) (*CheckTokenResponse, error) {
+	out := new(CheckTokenResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceJSONClient) Refresh(ctx context.Context, in *RefreshRequest) (*RefreshResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "Refresh")
+	caller := c.callRefresh
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RefreshRequest) (*RefreshResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshRequest) when calling interceptor")
+					}
+					return c.callRefresh(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceJSONClient) callRefresh(ctx context.Context, in *RefreshRequest) (*RefreshResponse, error) {
+	out := new(RefreshResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceJSONClient) Logout(ctx context.Context, in *LogoutRequest) (*LogoutResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "Logout")
+	caller := c.callLogout
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*LogoutRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*LogoutRequest) when calling interceptor")
+					}
+					return c.callLogout(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*LogoutResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*LogoutResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceJSONClient) callLogout(ctx context.Context, in *LogoutRequest) (*LogoutResponse, error) {
+	out := new(LogoutResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceJSONClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeToken")
+	caller := c.callRevokeToken
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeTokenRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeTokenRequest) when calling interceptor")
+					}
+					return c.callRevokeToken(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeTokenResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeTokenResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceJSONClient) callRevokeToken(ctx context.Context, in *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	out := new(RevokeTokenResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *authenticationServiceJSONClient) RotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithMethodName(ctx, "RotateSigningKey")
+	caller := c.callRotateSigningKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RotateSigningKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RotateSigningKeyRequest) when calling interceptor")
+					}
+					return c.callRotateSigningKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RotateSigningKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RotateSigningKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *authenticationServiceJSONClient) callRotateSigningKey(ctx context.Context, in *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
+	out := new(RotateSigningKeyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ====================================
+// AuthenticationService Server Handler
+// ====================================
+
+type authenticationServiceServer struct {
+	AuthenticationService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewAuthenticationServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewAuthenticationServiceServer(svc AuthenticationService, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &authenticationServiceServer{
+		AuthenticationService: svc,
+		hooks:                 serverOpts.Hooks,
+		interceptor:           twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:            pathPrefix,
+		jsonSkipDefaults:      jsonSkipDefaults,
+		jsonCamelCase:         jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *authenticationServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *authenticationServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// AuthenticationServicePathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const AuthenticationServicePathPrefix = "/twirp/auth.AuthenticationService/"
+
+func (s *authenticationServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "auth")
+	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "auth.AuthenticationService" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "Login":
+		s.serveLogin(ctx, resp, req)
+		return
+	case "CheckToken":
+		s.serveCheckToken(ctx, resp, req)
+		return
+	case "Refresh":
+		s.serveRefresh(ctx, resp, req)
+		return
+	case "Logout":
+		s.serveLogout(ctx, resp, req)
+		return
+	case "RevokeToken":
+		s.serveRevokeToken(ctx, resp, req)
+		return
+	case "RotateSigningKey":
+		s.serveRotateSigningKey(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *authenticationServiceServer) serveLogin(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveLoginJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveLoginProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Login")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(LoginRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AuthenticationService.Login
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*LoginRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*LoginRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.Login(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*LoginResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*LoginResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *LoginResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *LoginResponse and nil error while calling Login. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Login")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(LoginRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AuthenticationService.Login
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*LoginRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*LoginRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.Login(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*LoginResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*LoginResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *LoginResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *LoginResponse and nil error while calling Login. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveCheckToken(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCheckTokenJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCheckTokenProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(CheckTokenRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AuthenticationService.CheckToken
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CheckTokenRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.CheckToken(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CheckTokenResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CheckTokenResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CheckTokenResponse and nil error while calling CheckToken. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveCheckTokenProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(CheckTokenRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AuthenticationService.CheckToken
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CheckTokenRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.CheckToken(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CheckTokenResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CheckTokenResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CheckTokenResponse and nil error while calling CheckToken. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveRefresh(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRefreshJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRefreshProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *authenticationServiceServer) serveRefreshJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Refresh")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RefreshRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AuthenticationService.Refresh
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RefreshRequest) (*RefreshResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.Refresh(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RefreshResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RefreshResponse and nil error while calling Refresh. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveRefreshProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Refresh")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RefreshRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AuthenticationService.Refresh
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RefreshRequest) (*RefreshResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.Refresh(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RefreshResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RefreshResponse and nil error while calling Refresh. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *authenticationServiceServer) serveLogout(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveLogoutJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveLogoutProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *authenticationServiceServer) serveLogoutJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Logout")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(LogoutRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
 
-func (c *authenticationServiceJSONClient) CheckToken(ctx context.Context, in *CheckTokenRequest) (*CheckTokenResponse, error) {
-	ctx = ctxsetters.WithPackageName(ctx, "auth")
-	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
-	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
-	caller := c.callCheckToken
-	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
-			resp, err := c.interceptor(
+	handler := s.AuthenticationService.Logout
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error) {
+			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CheckTokenRequest)
+					typedReq, ok := req.(*LogoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*LogoutRequest) when calling interceptor")
 					}
-					return c.callCheckToken(ctx, typedReq)
+					return s.AuthenticationService.Logout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CheckTokenResponse)
+				typedResp, ok := resp.(*LogoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*LogoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
 			return nil, err
 		}
 	}
-	return caller(ctx, in)
-}
 
-func (c *authenticationServiceJSONClient) callCheckToken(ctx context.Context, in *CheckTokenRequest) (*CheckTokenResponse, error) {
-	out := new(CheckTokenResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	// Call service method
+	var respContent *LogoutResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
 	if err != nil {
-		twerr, ok := err.(twirp.Error)
-		if !ok {
-			twerr = twirp.InternalErrorWith(err)
-		}
-		callClientError(ctx, c.opts.Hooks, twerr)
-		return nil, err
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *LogoutResponse and nil error while calling Logout. nil responses are not supported"))
+		return
 	}
 
-	callClientResponseReceived(ctx, c.opts.Hooks)
-
-	return out, nil
-}
-
-// ====================================
-// AuthenticationService Server Handler
-// ====================================
-
-type authenticationServiceServer struct {
-	AuthenticationService
-	interceptor      twirp.Interceptor
-	hooks            *twirp.ServerHooks
-	pathPrefix       string // prefix for routing
-	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
-	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
-}
-
-// NewAuthenticationServiceServer builds a TwirpServer that can be used as an http.Handler to handle
-// HTTP requests that are routed to the right method in the provided svc implementation.
-// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
-func NewAuthenticationServiceServer(svc AuthenticationService, opts ...interface{}) TwirpServer {
-	serverOpts := newServerOpts(opts)
+	ctx = callResponsePrepared(ctx, s.hooks)
 
-	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	jsonSkipDefaults := false
-	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
-	jsonCamelCase := false
-	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
-	var pathPrefix string
-	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
 	}
 
-	return &authenticationServiceServer{
-		AuthenticationService: svc,
-		hooks:                 serverOpts.Hooks,
-		interceptor:           twirp.ChainInterceptors(serverOpts.Interceptors...),
-		pathPrefix:            pathPrefix,
-		jsonSkipDefaults:      jsonSkipDefaults,
-		jsonCamelCase:         jsonCamelCase,
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
 	}
+	callResponseSent(ctx, s.hooks)
 }
 
-// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
-// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
-func (s *authenticationServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
-	writeError(ctx, resp, err, s.hooks)
-}
+func (s *authenticationServiceServer) serveLogoutProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Logout")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
 
-// handleRequestBodyError is used to handle error when the twirp server cannot read request
-func (s *authenticationServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
-	if context.Canceled == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	if context.DeadlineExceeded == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+	reqContent := new(LogoutRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
-	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
-}
 
-// AuthenticationServicePathPrefix is a convenience constant that may identify URL paths.
-// Should be used with caution, it only matches routes generated by Twirp Go clients,
-// with the default "/twirp" prefix and default CamelCase service and method names.
-// More info: https://twitchtv.github.io/twirp/docs/routing.html
-const AuthenticationServicePathPrefix = "/twirp/auth.AuthenticationService/"
+	handler := s.AuthenticationService.Logout
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*LogoutRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*LogoutRequest) when calling interceptor")
+					}
+					return s.AuthenticationService.Logout(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*LogoutResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*LogoutResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
 
-func (s *authenticationServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-	ctx = ctxsetters.WithPackageName(ctx, "auth")
-	ctx = ctxsetters.WithServiceName(ctx, "AuthenticationService")
-	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+	// Call service method
+	var respContent *LogoutResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
 
-	var err error
-	ctx, err = callRequestReceived(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
-
-	if req.Method != "POST" {
-		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *LogoutResponse and nil error while calling Logout. nil responses are not supported"))
 		return
 	}
 
-	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
-	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
-	if pkgService != "auth.AuthenticationService" {
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
-		return
-	}
-	if prefix != s.pathPrefix {
-		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
 		return
 	}
 
-	switch method {
-	case "Login":
-		s.serveLogin(ctx, resp, req)
-		return
-	case "CheckToken":
-		s.serveCheckToken(ctx, resp, req)
-		return
-	default:
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
-		return
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
 	}
+	callResponseSent(ctx, s.hooks)
 }
 
-func (s *authenticationServiceServer) serveLogin(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRevokeToken(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -433,9 +1549,9 @@ func (s *authenticationServiceServer) serveLogin(ctx context.Context, resp http.
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveLoginJSON(ctx, resp, req)
+		s.serveRevokeTokenJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveLoginProtobuf(ctx, resp, req)
+		s.serveRevokeTokenProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -443,9 +1559,9 @@ func (s *authenticationServiceServer) serveLogin(ctx context.Context, resp http.
 	}
 }
 
-func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRevokeTokenJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Login")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeToken")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -458,29 +1574,29 @@ func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp h
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(LoginRequest)
+	reqContent := new(RevokeTokenRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.AuthenticationService.Login
+	handler := s.AuthenticationService.RevokeToken
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+		handler = func(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*LoginRequest)
+					typedReq, ok := req.(*RevokeTokenRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*LoginRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeTokenRequest) when calling interceptor")
 					}
-					return s.AuthenticationService.Login(ctx, typedReq)
+					return s.AuthenticationService.RevokeToken(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*LoginResponse)
+				typedResp, ok := resp.(*RevokeTokenResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*LoginResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeTokenResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -489,7 +1605,7 @@ func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp h
 	}
 
 	// Call service method
-	var respContent *LoginResponse
+	var respContent *RevokeTokenResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -500,7 +1616,7 @@ func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp h
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *LoginResponse and nil error while calling Login. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeTokenResponse and nil error while calling RevokeToken. nil responses are not supported"))
 		return
 	}
 
@@ -526,9 +1642,9 @@ func (s *authenticationServiceServer) serveLoginJSON(ctx context.Context, resp h
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRevokeTokenProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Login")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeToken")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -540,28 +1656,28 @@ func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, re
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(LoginRequest)
+	reqContent := new(RevokeTokenRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.AuthenticationService.Login
+	handler := s.AuthenticationService.RevokeToken
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+		handler = func(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*LoginRequest)
+					typedReq, ok := req.(*RevokeTokenRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*LoginRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeTokenRequest) when calling interceptor")
 					}
-					return s.AuthenticationService.Login(ctx, typedReq)
+					return s.AuthenticationService.RevokeToken(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*LoginResponse)
+				typedResp, ok := resp.(*RevokeTokenResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*LoginResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeTokenResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -570,7 +1686,7 @@ func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, re
 	}
 
 	// Call service method
-	var respContent *LoginResponse
+	var respContent *RevokeTokenResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -581,7 +1697,7 @@ func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, re
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *LoginResponse and nil error while calling Login. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeTokenResponse and nil error while calling RevokeToken. nil responses are not supported"))
 		return
 	}
 
@@ -605,7 +1721,7 @@ func (s *authenticationServiceServer) serveLoginProtobuf(ctx context.Context, re
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *authenticationServiceServer) serveCheckToken(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRotateSigningKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -613,9 +1729,9 @@ func (s *authenticationServiceServer) serveCheckToken(ctx context.Context, resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveCheckTokenJSON(ctx, resp, req)
+		s.serveRotateSigningKeyJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveCheckTokenProtobuf(ctx, resp, req)
+		s.serveRotateSigningKeyProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -623,9 +1739,9 @@ func (s *authenticationServiceServer) serveCheckToken(ctx context.Context, resp
 	}
 }
 
-func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRotateSigningKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
+	ctx = ctxsetters.WithMethodName(ctx, "RotateSigningKey")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -638,29 +1754,29 @@ func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, r
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(CheckTokenRequest)
+	reqContent := new(RotateSigningKeyRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.AuthenticationService.CheckToken
+	handler := s.AuthenticationService.RotateSigningKey
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
+		handler = func(ctx context.Context, req *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CheckTokenRequest)
+					typedReq, ok := req.(*RotateSigningKeyRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RotateSigningKeyRequest) when calling interceptor")
 					}
-					return s.AuthenticationService.CheckToken(ctx, typedReq)
+					return s.AuthenticationService.RotateSigningKey(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CheckTokenResponse)
+				typedResp, ok := resp.(*RotateSigningKeyResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RotateSigningKeyResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -669,7 +1785,7 @@ func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, r
 	}
 
 	// Call service method
-	var respContent *CheckTokenResponse
+	var respContent *RotateSigningKeyResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -680,7 +1796,7 @@ func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, r
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *CheckTokenResponse and nil error while calling CheckToken. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RotateSigningKeyResponse and nil error while calling RotateSigningKey. nil responses are not supported"))
 		return
 	}
 
@@ -706,9 +1822,9 @@ func (s *authenticationServiceServer) serveCheckTokenJSON(ctx context.Context, r
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *authenticationServiceServer) serveCheckTokenProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *authenticationServiceServer) serveRotateSigningKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "CheckToken")
+	ctx = ctxsetters.WithMethodName(ctx, "RotateSigningKey")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -720,28 +1836,28 @@ func (s *authenticationServiceServer) serveCheckTokenProtobuf(ctx context.Contex
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(CheckTokenRequest)
+	reqContent := new(RotateSigningKeyRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.AuthenticationService.CheckToken
+	handler := s.AuthenticationService.RotateSigningKey
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *CheckTokenRequest) (*CheckTokenResponse, error) {
+		handler = func(ctx context.Context, req *RotateSigningKeyRequest) (*RotateSigningKeyResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CheckTokenRequest)
+					typedReq, ok := req.(*RotateSigningKeyRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CheckTokenRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RotateSigningKeyRequest) when calling interceptor")
 					}
-					return s.AuthenticationService.CheckToken(ctx, typedReq)
+					return s.AuthenticationService.RotateSigningKey(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CheckTokenResponse)
+				typedResp, ok := resp.(*RotateSigningKeyResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CheckTokenResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RotateSigningKeyResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -750,7 +1866,7 @@ func (s *authenticationServiceServer) serveCheckTokenProtobuf(ctx context.Contex
 	}
 
 	// Call service method
-	var respContent *CheckTokenResponse
+	var respContent *RotateSigningKeyResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -761,7 +1877,7 @@ func (s *authenticationServiceServer) serveCheckTokenProtobuf(ctx context.Contex
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *CheckTokenResponse and nil error while calling CheckToken. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RotateSigningKeyResponse and nil error while calling RotateSigningKey. nil responses are not supported"))
 		return
 	}
 
@@ -1366,21 +2482,41 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 243 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2b, 0x2a, 0x48, 0xd6,
-	0x4f, 0x2c, 0x2d, 0xc9, 0xd0, 0x2f, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c, 0x4e, 0xd5, 0x2b, 0x28, 0xca,
-	0x2f, 0xc9, 0x17, 0x62, 0x01, 0x89, 0x29, 0xb9, 0x71, 0xf1, 0xf8, 0xe4, 0xa7, 0x67, 0xe6, 0x05,
-	0xa5, 0x16, 0x96, 0xa6, 0x16, 0x97, 0x08, 0x49, 0x71, 0x71, 0x94, 0x16, 0xa7, 0x16, 0xe5, 0x25,
-	0xe6, 0xa6, 0x4a, 0x30, 0x2a, 0x30, 0x6a, 0x70, 0x06, 0xc1, 0xf9, 0x20, 0xb9, 0x82, 0xc4, 0xe2,
-	0xe2, 0xf2, 0xfc, 0xa2, 0x14, 0x09, 0x26, 0x88, 0x1c, 0x8c, 0xaf, 0xe4, 0xc8, 0xc5, 0x0b, 0x35,
-	0xa7, 0xb8, 0x20, 0x3f, 0xaf, 0x38, 0x55, 0x48, 0x84, 0x8b, 0xb5, 0x24, 0x3f, 0x3b, 0x35, 0x0f,
-	0x6a, 0x0a, 0x84, 0x83, 0x62, 0x3c, 0x13, 0xaa, 0xf1, 0x4a, 0x9a, 0x5c, 0x82, 0xce, 0x19, 0xa9,
-	0xc9, 0xd9, 0x21, 0x20, 0x95, 0x30, 0xf7, 0x60, 0x35, 0x46, 0xc9, 0x80, 0x4b, 0x08, 0x59, 0x29,
-	0xd4, 0x4a, 0x3c, 0x6e, 0x37, 0xea, 0x62, 0xe4, 0x12, 0x75, 0x2c, 0x2d, 0xc9, 0x48, 0xcd, 0x2b,
-	0xc9, 0x4c, 0x4e, 0x2c, 0xc9, 0xcc, 0xcf, 0x0b, 0x86, 0x84, 0x86, 0x90, 0x01, 0x17, 0x2b, 0xd8,
-	0xe5, 0x42, 0x42, 0x7a, 0xa0, 0x10, 0xd1, 0x43, 0x0e, 0x0e, 0x29, 0x61, 0x14, 0x31, 0xa8, 0x3d,
-	0xf6, 0x5c, 0x5c, 0x08, 0xdb, 0x85, 0xc4, 0x21, 0x4a, 0x30, 0x9c, 0x2e, 0x25, 0x81, 0x29, 0x01,
-	0x31, 0xc0, 0x49, 0x2c, 0x4a, 0x44, 0x1f, 0x1c, 0x09, 0x49, 0xa5, 0x69, 0x10, 0x46, 0x3c, 0x48,
-	0x69, 0x12, 0x1b, 0x98, 0x6d, 0x0c, 0x08, 0x00, 0x00, 0xff, 0xff, 0xe1, 0xf7, 0xf5, 0xda, 0xb3,
-	0x01, 0x00, 0x00,
+	// 566 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x96, 0xe3, 0xa4, 0x4d, 0xa6, 0x3f, 0x84, 0x6d, 0x9a, 0x6e, 0x2d, 0x15, 0x22, 0x73, 0x29,
+	0x15, 0x4a, 0x10, 0x05, 0xc4, 0x0d, 0xb5, 0x3d, 0x42, 0x0f, 0xb8, 0x9c, 0xb8, 0x58, 0x8e, 0x33,
+	0x4d, 0x16, 0x13, 0x6f, 0xba, 0xbb, 0x2e, 0xf0, 0x06, 0x48, 0x48, 0xbc, 0x05, 0xcf, 0xc1, 0xab,
+	0x21, 0xef, 0xae, 0x9b, 0x1f, 0x87, 0x84, 0xde, 0x66, 0xe6, 0x9b, 0xf9, 0x3c, 0x33, 0xdf, 0x78,
+	0xa1, 0x2d, 0x26, 0x71, 0x2f, 0xca, 0xd4, 0xa8, 0x27, 0x51, 0xdc, 0xb2, 0x18, 0xbb, 0x13, 0xc1,
+	0x15, 0x27, 0xd5, 0x3c, 0xe6, 0xff, 0x70, 0x60, 0xfb, 0x3d, 0x1f, 0xb2, 0x34, 0xc0, 0x9b, 0x0c,
+	0xa5, 0x22, 0x1e, 0xd4, 0x33, 0x89, 0x22, 0x8d, 0xc6, 0x48, 0x9d, 0x8e, 0x73, 0xdc, 0x08, 0xee,
+	0xfc, 0x1c, 0x9b, 0x44, 0x52, 0x7e, 0xe5, 0x62, 0x40, 0x2b, 0x06, 0x2b, 0x7c, 0xf2, 0x18, 0xb6,
+	0x04, 0x8e, 0x71, 0xdc, 0x47, 0x11, 0x8e, 0x91, 0xba, 0x1d, 0xe7, 0xb8, 0x1e, 0x40, 0x11, 0xba,
+	0xc4, 0x3c, 0x21, 0xe6, 0x3c, 0x61, 0x18, 0x8e, 0xf9, 0x00, 0x69, 0xd5, 0x24, 0x98, 0xd0, 0x25,
+	0x1f, 0xa0, 0xff, 0xc7, 0x81, 0x1d, 0xdb, 0x8a, 0x9c, 0xf0, 0x54, 0x22, 0x69, 0x41, 0x4d, 0xf1,
+	0x04, 0x53, 0xdb, 0x88, 0x71, 0xe6, 0x3a, 0xac, 0x2c, 0x74, 0xf8, 0x04, 0x76, 0x04, 0x5e, 0x0b,
+	0x94, 0xa3, 0xd0, 0x54, 0xba, 0x3a, 0x61, 0xdb, 0x06, 0x3f, 0x6a, 0x82, 0x23, 0x00, 0xfc, 0x36,
+	0x61, 0x02, 0x65, 0x18, 0x29, 0xdd, 0x88, 0x1b, 0x34, 0x6c, 0xe4, 0x4c, 0x91, 0x37, 0x40, 0x8b,
+	0xa9, 0xc2, 0x78, 0x14, 0xa5, 0x43, 0x0c, 0x05, 0xde, 0x64, 0x4c, 0xe0, 0x80, 0xd6, 0x74, 0xd7,
+	0xed, 0x02, 0xbf, 0xd0, 0x70, 0x60, 0x51, 0xff, 0x29, 0x3c, 0xbc, 0x18, 0x61, 0x9c, 0xe8, 0xcf,
+	0x14, 0x0b, 0x5d, 0x3a, 0x84, 0xff, 0xd3, 0x01, 0x32, 0x9b, 0x6b, 0x27, 0x5e, 0xb5, 0x7d, 0x02,
+	0xd5, 0x2c, 0x63, 0xc5, 0xe6, 0xb5, 0x9d, 0x93, 0x0b, 0xfe, 0x05, 0x25, 0x75, 0x3b, 0x6e, 0x4e,
+	0xae, 0x9d, 0x75, 0x03, 0x36, 0xc1, 0xfd, 0x9c, 0x28, 0x3d, 0x4b, 0x23, 0xc8, 0x4d, 0xff, 0x15,
+	0xec, 0x06, 0x66, 0x43, 0x45, 0xd7, 0xa5, 0x45, 0x3a, 0xe5, 0x45, 0xfa, 0xbf, 0x1d, 0x78, 0x70,
+	0x57, 0xb7, 0x52, 0xb3, 0x12, 0x5d, 0x65, 0xad, 0x2e, 0xee, 0x7d, 0x74, 0xa9, 0xae, 0xd4, 0xe5,
+	0xa5, 0x3e, 0x2c, 0x9e, 0xa9, 0x7b, 0x4d, 0x77, 0x02, 0xbb, 0x45, 0x95, 0x9d, 0x8d, 0xc2, 0xa6,
+	0xcc, 0xe2, 0x18, 0xa5, 0xd4, 0x05, 0xf5, 0xa0, 0x70, 0xfd, 0x13, 0x20, 0x01, 0xde, 0xf2, 0x04,
+	0xff, 0x43, 0xfa, 0x1e, 0xec, 0xcd, 0xe5, 0xae, 0x25, 0x3f, 0x84, 0x83, 0x80, 0xab, 0x48, 0xe1,
+	0x15, 0x1b, 0xa6, 0x2c, 0x1d, 0xbe, 0xc3, 0xef, 0xf6, 0x0b, 0xfe, 0x33, 0xa0, 0x65, 0xc8, 0x12,
+	0x36, 0xc1, 0x4d, 0xd8, 0xc0, 0x7e, 0x3b, 0x37, 0x5f, 0xfc, 0x72, 0x61, 0xff, 0x2c, 0x53, 0x23,
+	0x4c, 0x15, 0x8b, 0x23, 0xc5, 0x78, 0x7a, 0x65, 0x9e, 0x04, 0xf2, 0x1c, 0x6a, 0xfa, 0xd7, 0x23,
+	0xa4, 0x9b, 0x3f, 0x0b, 0xdd, 0xd9, 0x27, 0xc1, 0xdb, 0x9b, 0x8b, 0x59, 0xf6, 0xb7, 0x00, 0xd3,
+	0xfb, 0x25, 0x07, 0x26, 0xa5, 0x74, 0xfd, 0x1e, 0x2d, 0x03, 0x96, 0xe0, 0x35, 0x6c, 0xda, 0xdb,
+	0x21, 0x2d, 0x93, 0x34, 0x7f, 0x82, 0xde, 0xfe, 0x42, 0xd4, 0xd6, 0x9d, 0xc2, 0x86, 0x91, 0x85,
+	0x4c, 0xfb, 0x9a, 0x4a, 0xeb, 0xb5, 0xe6, 0x83, 0xb6, 0xe8, 0x1c, 0xb6, 0x66, 0x76, 0x4e, 0x68,
+	0x41, 0xbd, 0x28, 0x99, 0x77, 0xb8, 0x04, 0xb1, 0x1c, 0x1f, 0xa0, 0xb9, 0xb8, 0x6b, 0x72, 0x64,
+	0xd3, 0x97, 0xcb, 0xe3, 0x3d, 0xfa, 0x17, 0x6c, 0x28, 0xcf, 0xdb, 0x9f, 0x5a, 0x3d, 0xfd, 0x1a,
+	0xf7, 0xb3, 0x6b, 0x63, 0x84, 0x79, 0x41, 0x7f, 0x43, 0xdb, 0xa7, 0x7f, 0x07, 0x00, 0xf1, 0x45,
+	0x9f, 0x8c, 0xbc, 0x05, 0x00, 0x00,
 }
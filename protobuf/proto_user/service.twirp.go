@@ -43,6 +43,22 @@ type UserService interface {
 	UpdatePassword(context.Context, *UpdatePasswordRequest) (*UpdatePasswordResponse, error)
 
 	UpdateEmail(context.Context, *UpdateEmailRequest) (*UpdateEmailResponse, error)
+
+	RevokeAllSessions(context.Context, *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error)
+
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error)
+
+	RevokeOtherSessions(context.Context, *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error)
+
+	Impersonate(context.Context, *ImpersonateRequest) (*ImpersonateResponse, error)
+
+	RequirePasswordChange(context.Context, *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error)
+
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error)
+
+	ConfirmPasswordReset(context.Context, *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error)
 }
 
 // ===========================
@@ -51,7 +67,7 @@ type UserService interface {
 
 type userServiceProtobufClient struct {
 	client      HTTPClient
-	urls        [6]string
+	urls        [14]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -79,13 +95,21 @@ func NewUserServiceProtobufClient(baseURL string, client HTTPClient, opts ...twi
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "user", "UserService")
-	urls := [6]string{
+	urls := [14]string{
 		serviceURL + "Register",
 		serviceURL + "Ban",
 		serviceURL + "Unban",
 		serviceURL + "Delete",
 		serviceURL + "UpdatePassword",
 		serviceURL + "UpdateEmail",
+		serviceURL + "RevokeAllSessions",
+		serviceURL + "ListSessions",
+		serviceURL + "RevokeSession",
+		serviceURL + "RevokeOtherSessions",
+		serviceURL + "Impersonate",
+		serviceURL + "RequirePasswordChange",
+		serviceURL + "RequestPasswordReset",
+		serviceURL + "ConfirmPasswordReset",
 	}
 
 	return &userServiceProtobufClient{
@@ -372,77 +396,118 @@ func (c *userServiceProtobufClient) callUpdateEmail(ctx context.Context, in *Upd
 	return out, nil
 }
 
-// =======================
-// UserService JSON Client
-// =======================
-
-type userServiceJSONClient struct {
-	client      HTTPClient
-	urls        [6]string
-	interceptor twirp.Interceptor
-	opts        twirp.ClientOptions
+func (c *userServiceProtobufClient) RevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeAllSessions")
+	caller := c.callRevokeAllSessions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeAllSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeAllSessionsRequest) when calling interceptor")
+					}
+					return c.callRevokeAllSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeAllSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeAllSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
 }
 
-// NewUserServiceJSONClient creates a JSON client that implements the UserService interface.
-// It communicates using JSON and can be configured with a custom HTTPClient.
-func NewUserServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) UserService {
-	if c, ok := client.(*http.Client); ok {
-		client = withoutRedirects(c)
+func (c *userServiceProtobufClient) callRevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+	out := new(RevokeAllSessionsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
 	}
 
-	clientOpts := twirp.ClientOptions{}
-	for _, o := range opts {
-		o(&clientOpts)
-	}
+	callClientResponseReceived(ctx, c.opts.Hooks)
 
-	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	literalURLs := false
-	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
-	var pathPrefix string
-	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
-	}
+	return out, nil
+}
 
-	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
-	serviceURL := sanitizeBaseURL(baseURL)
-	serviceURL += baseServicePath(pathPrefix, "user", "UserService")
-	urls := [6]string{
-		serviceURL + "Register",
-		serviceURL + "Ban",
-		serviceURL + "Unban",
-		serviceURL + "Delete",
-		serviceURL + "UpdatePassword",
-		serviceURL + "UpdateEmail",
+func (c *userServiceProtobufClient) ListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListSessions")
+	caller := c.callListSessions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListSessionsRequest) when calling interceptor")
+					}
+					return c.callListSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
 	}
+	return caller(ctx, in)
+}
 
-	return &userServiceJSONClient{
-		client:      client,
-		urls:        urls,
-		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
-		opts:        clientOpts,
+func (c *userServiceProtobufClient) callListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
 	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
 }
 
-func (c *userServiceJSONClient) Register(ctx context.Context, in *RegisterRequest) (*RegisterResponse, error) {
+func (c *userServiceProtobufClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "Register")
-	caller := c.callRegister
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeSession")
+	caller := c.callRevokeSession
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+		caller = func(ctx context.Context, req *RevokeSessionRequest) (*RevokeSessionResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*RegisterRequest)
+					typedReq, ok := req.(*RevokeSessionRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeSessionRequest) when calling interceptor")
 					}
-					return c.callRegister(ctx, typedReq)
+					return c.callRevokeSession(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*RegisterResponse)
+				typedResp, ok := resp.(*RevokeSessionResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeSessionResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -452,9 +517,9 @@ func (c *userServiceJSONClient) Register(ctx context.Context, in *RegisterReques
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callRegister(ctx context.Context, in *RegisterRequest) (*RegisterResponse, error) {
-	out := new(RegisterResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+func (c *userServiceProtobufClient) callRevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -469,26 +534,26 @@ func (c *userServiceJSONClient) callRegister(ctx context.Context, in *RegisterRe
 	return out, nil
 }
 
-func (c *userServiceJSONClient) Ban(ctx context.Context, in *BanRequest) (*BanResponse, error) {
+func (c *userServiceProtobufClient) RevokeOtherSessions(ctx context.Context, in *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "Ban")
-	caller := c.callBan
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeOtherSessions")
+	caller := c.callRevokeOtherSessions
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+		caller = func(ctx context.Context, req *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BanRequest)
+					typedReq, ok := req.(*RevokeOtherSessionsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeOtherSessionsRequest) when calling interceptor")
 					}
-					return c.callBan(ctx, typedReq)
+					return c.callRevokeOtherSessions(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BanResponse)
+				typedResp, ok := resp.(*RevokeOtherSessionsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeOtherSessionsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -498,9 +563,9 @@ func (c *userServiceJSONClient) Ban(ctx context.Context, in *BanRequest) (*BanRe
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callBan(ctx context.Context, in *BanRequest) (*BanResponse, error) {
-	out := new(BanResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+func (c *userServiceProtobufClient) callRevokeOtherSessions(ctx context.Context, in *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
+	out := new(RevokeOtherSessionsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -515,26 +580,26 @@ func (c *userServiceJSONClient) callBan(ctx context.Context, in *BanRequest) (*B
 	return out, nil
 }
 
-func (c *userServiceJSONClient) Unban(ctx context.Context, in *UnbanRequest) (*UnbanResponse, error) {
+func (c *userServiceProtobufClient) Impersonate(ctx context.Context, in *ImpersonateRequest) (*ImpersonateResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "Unban")
-	caller := c.callUnban
+	ctx = ctxsetters.WithMethodName(ctx, "Impersonate")
+	caller := c.callImpersonate
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+		caller = func(ctx context.Context, req *ImpersonateRequest) (*ImpersonateResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UnbanRequest)
+					typedReq, ok := req.(*ImpersonateRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ImpersonateRequest) when calling interceptor")
 					}
-					return c.callUnban(ctx, typedReq)
+					return c.callImpersonate(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UnbanResponse)
+				typedResp, ok := resp.(*ImpersonateResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ImpersonateResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -544,9 +609,9 @@ func (c *userServiceJSONClient) Unban(ctx context.Context, in *UnbanRequest) (*U
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callUnban(ctx context.Context, in *UnbanRequest) (*UnbanResponse, error) {
-	out := new(UnbanResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+func (c *userServiceProtobufClient) callImpersonate(ctx context.Context, in *ImpersonateRequest) (*ImpersonateResponse, error) {
+	out := new(ImpersonateResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -561,26 +626,26 @@ func (c *userServiceJSONClient) callUnban(ctx context.Context, in *UnbanRequest)
 	return out, nil
 }
 
-func (c *userServiceJSONClient) Delete(ctx context.Context, in *DeleteRequest) (*DeleteResponse, error) {
+func (c *userServiceProtobufClient) RequirePasswordChange(ctx context.Context, in *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "Delete")
-	caller := c.callDelete
+	ctx = ctxsetters.WithMethodName(ctx, "RequirePasswordChange")
+	caller := c.callRequirePasswordChange
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+		caller = func(ctx context.Context, req *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteRequest)
+					typedReq, ok := req.(*RequirePasswordChangeRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequirePasswordChangeRequest) when calling interceptor")
 					}
-					return c.callDelete(ctx, typedReq)
+					return c.callRequirePasswordChange(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteResponse)
+				typedResp, ok := resp.(*RequirePasswordChangeResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequirePasswordChangeResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -590,9 +655,9 @@ func (c *userServiceJSONClient) Delete(ctx context.Context, in *DeleteRequest) (
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callDelete(ctx context.Context, in *DeleteRequest) (*DeleteResponse, error) {
-	out := new(DeleteResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+func (c *userServiceProtobufClient) callRequirePasswordChange(ctx context.Context, in *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
+	out := new(RequirePasswordChangeResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -607,26 +672,26 @@ func (c *userServiceJSONClient) callDelete(ctx context.Context, in *DeleteReques
 	return out, nil
 }
 
-func (c *userServiceJSONClient) UpdatePassword(ctx context.Context, in *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+func (c *userServiceProtobufClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
-	caller := c.callUpdatePassword
+	ctx = ctxsetters.WithMethodName(ctx, "RequestPasswordReset")
+	caller := c.callRequestPasswordReset
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+		caller = func(ctx context.Context, req *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdatePasswordRequest)
+					typedReq, ok := req.(*RequestPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequestPasswordResetRequest) when calling interceptor")
 					}
-					return c.callUpdatePassword(ctx, typedReq)
+					return c.callRequestPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdatePasswordResponse)
+				typedResp, ok := resp.(*RequestPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequestPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -636,9 +701,9 @@ func (c *userServiceJSONClient) UpdatePassword(ctx context.Context, in *UpdatePa
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callUpdatePassword(ctx context.Context, in *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
-	out := new(UpdatePasswordResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+func (c *userServiceProtobufClient) callRequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
+	out := new(RequestPasswordResetResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -653,26 +718,26 @@ func (c *userServiceJSONClient) callUpdatePassword(ctx context.Context, in *Upda
 	return out, nil
 }
 
-func (c *userServiceJSONClient) UpdateEmail(ctx context.Context, in *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+func (c *userServiceProtobufClient) ConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "user")
 	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
-	caller := c.callUpdateEmail
+	ctx = ctxsetters.WithMethodName(ctx, "ConfirmPasswordReset")
+	caller := c.callConfirmPasswordReset
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+		caller = func(ctx context.Context, req *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdateEmailRequest)
+					typedReq, ok := req.(*ConfirmPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ConfirmPasswordResetRequest) when calling interceptor")
 					}
-					return c.callUpdateEmail(ctx, typedReq)
+					return c.callConfirmPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdateEmailResponse)
+				typedResp, ok := resp.(*ConfirmPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ConfirmPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -682,9 +747,9 @@ func (c *userServiceJSONClient) UpdateEmail(ctx context.Context, in *UpdateEmail
 	return caller(ctx, in)
 }
 
-func (c *userServiceJSONClient) callUpdateEmail(ctx context.Context, in *UpdateEmailRequest) (*UpdateEmailResponse, error) {
-	out := new(UpdateEmailResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+func (c *userServiceProtobufClient) callConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
+	out := new(ConfirmPasswordResetResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -699,129 +764,2296 @@ func (c *userServiceJSONClient) callUpdateEmail(ctx context.Context, in *UpdateE
 	return out, nil
 }
 
-// ==========================
-// UserService Server Handler
-// ==========================
+// =======================
+// UserService JSON Client
+// =======================
 
-type userServiceServer struct {
-	UserService
-	interceptor      twirp.Interceptor
-	hooks            *twirp.ServerHooks
-	pathPrefix       string // prefix for routing
-	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
-	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+type userServiceJSONClient struct {
+	client      HTTPClient
+	urls        [14]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
 }
 
-// NewUserServiceServer builds a TwirpServer that can be used as an http.Handler to handle
-// HTTP requests that are routed to the right method in the provided svc implementation.
-// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
-func NewUserServiceServer(svc UserService, opts ...interface{}) TwirpServer {
-	serverOpts := newServerOpts(opts)
+// NewUserServiceJSONClient creates a JSON client that implements the UserService interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewUserServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) UserService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
 
 	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	jsonSkipDefaults := false
-	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
-	jsonCamelCase := false
-	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
 	var pathPrefix string
-	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
 		pathPrefix = "/twirp" // default prefix
 	}
 
-	return &userServiceServer{
-		UserService:      svc,
-		hooks:            serverOpts.Hooks,
-		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
-		pathPrefix:       pathPrefix,
-		jsonSkipDefaults: jsonSkipDefaults,
-		jsonCamelCase:    jsonCamelCase,
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "user", "UserService")
+	urls := [14]string{
+		serviceURL + "Register",
+		serviceURL + "Ban",
+		serviceURL + "Unban",
+		serviceURL + "Delete",
+		serviceURL + "UpdatePassword",
+		serviceURL + "UpdateEmail",
+		serviceURL + "RevokeAllSessions",
+		serviceURL + "ListSessions",
+		serviceURL + "RevokeSession",
+		serviceURL + "RevokeOtherSessions",
+		serviceURL + "Impersonate",
+		serviceURL + "RequirePasswordChange",
+		serviceURL + "RequestPasswordReset",
+		serviceURL + "ConfirmPasswordReset",
 	}
-}
 
-// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
-// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
-func (s *userServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
-	writeError(ctx, resp, err, s.hooks)
+	return &userServiceJSONClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
 }
 
-// handleRequestBodyError is used to handle error when the twirp server cannot read request
-func (s *userServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
-	if context.Canceled == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+func (c *userServiceJSONClient) Register(ctx context.Context, in *RegisterRequest) (*RegisterResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "Register")
+	caller := c.callRegister
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RegisterRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+					}
+					return c.callRegister(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RegisterResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRegister(ctx context.Context, in *RegisterRequest) (*RegisterResponse, error) {
+	out := new(RegisterResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) Ban(ctx context.Context, in *BanRequest) (*BanResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "Ban")
+	caller := c.callBan
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+					}
+					return c.callBan(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callBan(ctx context.Context, in *BanRequest) (*BanResponse, error) {
+	out := new(BanResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) Unban(ctx context.Context, in *UnbanRequest) (*UnbanResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "Unban")
+	caller := c.callUnban
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnbanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+					}
+					return c.callUnban(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnbanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callUnban(ctx context.Context, in *UnbanRequest) (*UnbanResponse, error) {
+	out := new(UnbanResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) Delete(ctx context.Context, in *DeleteRequest) (*DeleteResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "Delete")
+	caller := c.callDelete
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+					}
+					return c.callDelete(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callDelete(ctx context.Context, in *DeleteRequest) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) UpdatePassword(ctx context.Context, in *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
+	caller := c.callUpdatePassword
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePasswordRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+					}
+					return c.callUpdatePassword(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePasswordResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callUpdatePassword(ctx context.Context, in *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+	out := new(UpdatePasswordResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) UpdateEmail(ctx context.Context, in *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
+	caller := c.callUpdateEmail
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateEmailRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+					}
+					return c.callUpdateEmail(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateEmailResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callUpdateEmail(ctx context.Context, in *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+	out := new(UpdateEmailResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) RevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeAllSessions")
+	caller := c.callRevokeAllSessions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeAllSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeAllSessionsRequest) when calling interceptor")
+					}
+					return c.callRevokeAllSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeAllSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeAllSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+	out := new(RevokeAllSessionsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) ListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListSessions")
+	caller := c.callListSessions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListSessionsRequest) when calling interceptor")
+					}
+					return c.callListSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeSession")
+	caller := c.callRevokeSession
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeSessionRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeSessionRequest) when calling interceptor")
+					}
+					return c.callRevokeSession(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeSessionResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeSessionResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) RevokeOtherSessions(ctx context.Context, in *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeOtherSessions")
+	caller := c.callRevokeOtherSessions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeOtherSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeOtherSessionsRequest) when calling interceptor")
+					}
+					return c.callRevokeOtherSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeOtherSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeOtherSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRevokeOtherSessions(ctx context.Context, in *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
+	out := new(RevokeOtherSessionsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) Impersonate(ctx context.Context, in *ImpersonateRequest) (*ImpersonateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "Impersonate")
+	caller := c.callImpersonate
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ImpersonateRequest) (*ImpersonateResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ImpersonateRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ImpersonateRequest) when calling interceptor")
+					}
+					return c.callImpersonate(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ImpersonateResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ImpersonateResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callImpersonate(ctx context.Context, in *ImpersonateRequest) (*ImpersonateResponse, error) {
+	out := new(ImpersonateResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) RequirePasswordChange(ctx context.Context, in *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RequirePasswordChange")
+	caller := c.callRequirePasswordChange
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RequirePasswordChangeRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RequirePasswordChangeRequest) when calling interceptor")
+					}
+					return c.callRequirePasswordChange(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RequirePasswordChangeResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RequirePasswordChangeResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRequirePasswordChange(ctx context.Context, in *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
+	out := new(RequirePasswordChangeResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "RequestPasswordReset")
+	caller := c.callRequestPasswordReset
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RequestPasswordResetRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RequestPasswordResetRequest) when calling interceptor")
+					}
+					return c.callRequestPasswordReset(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RequestPasswordResetResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RequestPasswordResetResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callRequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
+	out := new(RequestPasswordResetResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *userServiceJSONClient) ConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithMethodName(ctx, "ConfirmPasswordReset")
+	caller := c.callConfirmPasswordReset
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ConfirmPasswordResetRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ConfirmPasswordResetRequest) when calling interceptor")
+					}
+					return c.callConfirmPasswordReset(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ConfirmPasswordResetResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ConfirmPasswordResetResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *userServiceJSONClient) callConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
+	out := new(ConfirmPasswordResetResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ==========================
+// UserService Server Handler
+// ==========================
+
+type userServiceServer struct {
+	UserService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewUserServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewUserServiceServer(svc UserService, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &userServiceServer{
+		UserService:      svc,
+		hooks:            serverOpts.Hooks,
+		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:       pathPrefix,
+		jsonSkipDefaults: jsonSkipDefaults,
+		jsonCamelCase:    jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *userServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *userServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// UserServicePathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const UserServicePathPrefix = "/twirp/user.UserService/"
+
+func (s *userServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "user")
+	ctx = ctxsetters.WithServiceName(ctx, "UserService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "user.UserService" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "Register":
+		s.serveRegister(ctx, resp, req)
+		return
+	case "Ban":
+		s.serveBan(ctx, resp, req)
+		return
+	case "Unban":
+		s.serveUnban(ctx, resp, req)
+		return
+	case "Delete":
+		s.serveDelete(ctx, resp, req)
+		return
+	case "UpdatePassword":
+		s.serveUpdatePassword(ctx, resp, req)
+		return
+	case "UpdateEmail":
+		s.serveUpdateEmail(ctx, resp, req)
+		return
+	case "RevokeAllSessions":
+		s.serveRevokeAllSessions(ctx, resp, req)
+		return
+	case "ListSessions":
+		s.serveListSessions(ctx, resp, req)
+		return
+	case "RevokeSession":
+		s.serveRevokeSession(ctx, resp, req)
+		return
+	case "RevokeOtherSessions":
+		s.serveRevokeOtherSessions(ctx, resp, req)
+		return
+	case "Impersonate":
+		s.serveImpersonate(ctx, resp, req)
+		return
+	case "RequirePasswordChange":
+		s.serveRequirePasswordChange(ctx, resp, req)
+		return
+	case "RequestPasswordReset":
+		s.serveRequestPasswordReset(ctx, resp, req)
+		return
+	case "ConfirmPasswordReset":
+		s.serveConfirmPasswordReset(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *userServiceServer) serveRegister(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRegisterJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRegisterProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Register")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RegisterRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.Register
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RegisterRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+					}
+					return s.UserService.Register(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RegisterResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RegisterResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegisterResponse and nil error while calling Register. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Register")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RegisterRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.Register
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RegisterRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+					}
+					return s.UserService.Register(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RegisterResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RegisterResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegisterResponse and nil error while calling Register. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveBan(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveBanJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveBanProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Ban")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(BanRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.Ban
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+					}
+					return s.UserService.Ban(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BanResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BanResponse and nil error while calling Ban. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Ban")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(BanRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.Ban
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+					}
+					return s.UserService.Ban(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BanResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BanResponse and nil error while calling Ban. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUnban(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUnbanJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUnbanProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Unban")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UnbanRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.Unban
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnbanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+					}
+					return s.UserService.Unban(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnbanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UnbanResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnbanResponse and nil error while calling Unban. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Unban")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UnbanRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.Unban
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UnbanRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+					}
+					return s.UserService.Unban(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UnbanResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UnbanResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnbanResponse and nil error while calling Unban. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveDelete(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeleteJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Delete")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DeleteRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.Delete
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+					}
+					return s.UserService.Delete(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteResponse and nil error while calling Delete. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Delete")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DeleteRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.Delete
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+					}
+					return s.UserService.Delete(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteResponse and nil error while calling Delete. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUpdatePassword(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUpdatePasswordJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdatePasswordProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UpdatePasswordRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.UpdatePassword
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePasswordRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+					}
+					return s.UserService.UpdatePassword(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePasswordResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdatePasswordResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePasswordResponse and nil error while calling UpdatePassword. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UpdatePasswordRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.UpdatePassword
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePasswordRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+					}
+					return s.UserService.UpdatePassword(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePasswordResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdatePasswordResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePasswordResponse and nil error while calling UpdatePassword. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUpdateEmail(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUpdateEmailJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateEmailProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UpdateEmailRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.UpdateEmail
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateEmailRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+					}
+					return s.UserService.UpdateEmail(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateEmailResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateEmailResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateEmailResponse and nil error while calling UpdateEmail. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveUpdateEmailProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UpdateEmailRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.UpdateEmail
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateEmailRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+					}
+					return s.UserService.UpdateEmail(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateEmailResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateEmailResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateEmailResponse and nil error while calling UpdateEmail. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveRevokeAllSessions(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRevokeAllSessionsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRevokeAllSessionsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveRevokeAllSessionsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeAllSessions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RevokeAllSessionsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.RevokeAllSessions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeAllSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeAllSessionsRequest) when calling interceptor")
+					}
+					return s.UserService.RevokeAllSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeAllSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeAllSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RevokeAllSessionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeAllSessionsResponse and nil error while calling RevokeAllSessions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveRevokeAllSessionsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeAllSessions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RevokeAllSessionsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.UserService.RevokeAllSessions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeAllSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeAllSessionsRequest) when calling interceptor")
+					}
+					return s.UserService.RevokeAllSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeAllSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeAllSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RevokeAllSessionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeAllSessionsResponse and nil error while calling RevokeAllSessions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveListSessions(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListSessionsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListSessionsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *userServiceServer) serveListSessionsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListSessions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListSessionsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.UserService.ListSessions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListSessionsRequest) when calling interceptor")
+					}
+					return s.UserService.ListSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListSessionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListSessionsResponse and nil error while calling ListSessions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *userServiceServer) serveListSessionsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListSessions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	if context.DeadlineExceeded == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+	reqContent := new(ListSessionsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
-	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
-}
 
-// UserServicePathPrefix is a convenience constant that may identify URL paths.
-// Should be used with caution, it only matches routes generated by Twirp Go clients,
-// with the default "/twirp" prefix and default CamelCase service and method names.
-// More info: https://twitchtv.github.io/twirp/docs/routing.html
-const UserServicePathPrefix = "/twirp/user.UserService/"
+	handler := s.UserService.ListSessions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListSessionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListSessionsRequest) when calling interceptor")
+					}
+					return s.UserService.ListSessions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListSessionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListSessionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
 
-func (s *userServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-	ctx = ctxsetters.WithPackageName(ctx, "user")
-	ctx = ctxsetters.WithServiceName(ctx, "UserService")
-	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+	// Call service method
+	var respContent *ListSessionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
 
-	var err error
-	ctx, err = callRequestReceived(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
-
-	if req.Method != "POST" {
-		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListSessionsResponse and nil error while calling ListSessions. nil responses are not supported"))
 		return
 	}
 
-	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
-	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
-	if pkgService != "user.UserService" {
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
-		return
-	}
-	if prefix != s.pathPrefix {
-		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
 		return
 	}
 
-	switch method {
-	case "Register":
-		s.serveRegister(ctx, resp, req)
-		return
-	case "Ban":
-		s.serveBan(ctx, resp, req)
-		return
-	case "Unban":
-		s.serveUnban(ctx, resp, req)
-		return
-	case "Delete":
-		s.serveDelete(ctx, resp, req)
-		return
-	case "UpdatePassword":
-		s.serveUpdatePassword(ctx, resp, req)
-		return
-	case "UpdateEmail":
-		s.serveUpdateEmail(ctx, resp, req)
-		return
-	default:
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
-		return
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
 	}
+	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveRegister(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeSession(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -829,9 +3061,9 @@ func (s *userServiceServer) serveRegister(ctx context.Context, resp http.Respons
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveRegisterJSON(ctx, resp, req)
+		s.serveRevokeSessionJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveRegisterProtobuf(ctx, resp, req)
+		s.serveRevokeSessionProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -839,9 +3071,9 @@ func (s *userServiceServer) serveRegister(ctx context.Context, resp http.Respons
 	}
 }
 
-func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeSessionJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Register")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeSession")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -854,29 +3086,29 @@ func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.Res
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(RegisterRequest)
+	reqContent := new(RevokeSessionRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.Register
+	handler := s.UserService.RevokeSession
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+		handler = func(ctx context.Context, req *RevokeSessionRequest) (*RevokeSessionResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*RegisterRequest)
+					typedReq, ok := req.(*RevokeSessionRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeSessionRequest) when calling interceptor")
 					}
-					return s.UserService.Register(ctx, typedReq)
+					return s.UserService.RevokeSession(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*RegisterResponse)
+				typedResp, ok := resp.(*RevokeSessionResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeSessionResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -885,7 +3117,7 @@ func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.Res
 	}
 
 	// Call service method
-	var respContent *RegisterResponse
+	var respContent *RevokeSessionResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -896,7 +3128,7 @@ func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.Res
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegisterResponse and nil error while calling Register. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeSessionResponse and nil error while calling RevokeSession. nil responses are not supported"))
 		return
 	}
 
@@ -922,9 +3154,9 @@ func (s *userServiceServer) serveRegisterJSON(ctx context.Context, resp http.Res
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeSessionProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Register")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeSession")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -936,28 +3168,28 @@ func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(RegisterRequest)
+	reqContent := new(RevokeSessionRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.Register
+	handler := s.UserService.RevokeSession
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+		handler = func(ctx context.Context, req *RevokeSessionRequest) (*RevokeSessionResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*RegisterRequest)
+					typedReq, ok := req.(*RevokeSessionRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*RegisterRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeSessionRequest) when calling interceptor")
 					}
-					return s.UserService.Register(ctx, typedReq)
+					return s.UserService.RevokeSession(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*RegisterResponse)
+				typedResp, ok := resp.(*RevokeSessionResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*RegisterResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeSessionResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -966,7 +3198,7 @@ func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http
 	}
 
 	// Call service method
-	var respContent *RegisterResponse
+	var respContent *RevokeSessionResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -977,7 +3209,7 @@ func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegisterResponse and nil error while calling Register. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeSessionResponse and nil error while calling RevokeSession. nil responses are not supported"))
 		return
 	}
 
@@ -1001,7 +3233,7 @@ func (s *userServiceServer) serveRegisterProtobuf(ctx context.Context, resp http
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveBan(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeOtherSessions(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1009,9 +3241,9 @@ func (s *userServiceServer) serveBan(ctx context.Context, resp http.ResponseWrit
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveBanJSON(ctx, resp, req)
+		s.serveRevokeOtherSessionsJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveBanProtobuf(ctx, resp, req)
+		s.serveRevokeOtherSessionsProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1019,9 +3251,9 @@ func (s *userServiceServer) serveBan(ctx context.Context, resp http.ResponseWrit
 	}
 }
 
-func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeOtherSessionsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Ban")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeOtherSessions")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1034,29 +3266,29 @@ func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.Response
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(BanRequest)
+	reqContent := new(RevokeOtherSessionsRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.Ban
+	handler := s.UserService.RevokeOtherSessions
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+		handler = func(ctx context.Context, req *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BanRequest)
+					typedReq, ok := req.(*RevokeOtherSessionsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeOtherSessionsRequest) when calling interceptor")
 					}
-					return s.UserService.Ban(ctx, typedReq)
+					return s.UserService.RevokeOtherSessions(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BanResponse)
+				typedResp, ok := resp.(*RevokeOtherSessionsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeOtherSessionsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1065,7 +3297,7 @@ func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.Response
 	}
 
 	// Call service method
-	var respContent *BanResponse
+	var respContent *RevokeOtherSessionsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1076,7 +3308,7 @@ func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.Response
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *BanResponse and nil error while calling Ban. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeOtherSessionsResponse and nil error while calling RevokeOtherSessions. nil responses are not supported"))
 		return
 	}
 
@@ -1102,9 +3334,9 @@ func (s *userServiceServer) serveBanJSON(ctx context.Context, resp http.Response
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRevokeOtherSessionsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Ban")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeOtherSessions")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1116,28 +3348,28 @@ func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.Resp
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(BanRequest)
+	reqContent := new(RevokeOtherSessionsRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.Ban
+	handler := s.UserService.RevokeOtherSessions
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+		handler = func(ctx context.Context, req *RevokeOtherSessionsRequest) (*RevokeOtherSessionsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BanRequest)
+					typedReq, ok := req.(*RevokeOtherSessionsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeOtherSessionsRequest) when calling interceptor")
 					}
-					return s.UserService.Ban(ctx, typedReq)
+					return s.UserService.RevokeOtherSessions(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BanResponse)
+				typedResp, ok := resp.(*RevokeOtherSessionsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeOtherSessionsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1146,7 +3378,7 @@ func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.Resp
 	}
 
 	// Call service method
-	var respContent *BanResponse
+	var respContent *RevokeOtherSessionsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1157,7 +3389,7 @@ func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.Resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *BanResponse and nil error while calling Ban. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeOtherSessionsResponse and nil error while calling RevokeOtherSessions. nil responses are not supported"))
 		return
 	}
 
@@ -1181,7 +3413,7 @@ func (s *userServiceServer) serveBanProtobuf(ctx context.Context, resp http.Resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUnban(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveImpersonate(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1189,9 +3421,9 @@ func (s *userServiceServer) serveUnban(ctx context.Context, resp http.ResponseWr
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveUnbanJSON(ctx, resp, req)
+		s.serveImpersonateJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveUnbanProtobuf(ctx, resp, req)
+		s.serveImpersonateProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1199,9 +3431,9 @@ func (s *userServiceServer) serveUnban(ctx context.Context, resp http.ResponseWr
 	}
 }
 
-func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveImpersonateJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Unban")
+	ctx = ctxsetters.WithMethodName(ctx, "Impersonate")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1214,29 +3446,29 @@ func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.Respon
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(UnbanRequest)
+	reqContent := new(ImpersonateRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.Unban
+	handler := s.UserService.Impersonate
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+		handler = func(ctx context.Context, req *ImpersonateRequest) (*ImpersonateResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UnbanRequest)
+					typedReq, ok := req.(*ImpersonateRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ImpersonateRequest) when calling interceptor")
 					}
-					return s.UserService.Unban(ctx, typedReq)
+					return s.UserService.Impersonate(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UnbanResponse)
+				typedResp, ok := resp.(*ImpersonateResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ImpersonateResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1245,7 +3477,7 @@ func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.Respon
 	}
 
 	// Call service method
-	var respContent *UnbanResponse
+	var respContent *ImpersonateResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1256,7 +3488,7 @@ func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.Respon
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnbanResponse and nil error while calling Unban. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImpersonateResponse and nil error while calling Impersonate. nil responses are not supported"))
 		return
 	}
 
@@ -1282,9 +3514,9 @@ func (s *userServiceServer) serveUnbanJSON(ctx context.Context, resp http.Respon
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveImpersonateProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Unban")
+	ctx = ctxsetters.WithMethodName(ctx, "Impersonate")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1296,28 +3528,28 @@ func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.Re
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(UnbanRequest)
+	reqContent := new(ImpersonateRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.Unban
+	handler := s.UserService.Impersonate
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+		handler = func(ctx context.Context, req *ImpersonateRequest) (*ImpersonateResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UnbanRequest)
+					typedReq, ok := req.(*ImpersonateRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UnbanRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ImpersonateRequest) when calling interceptor")
 					}
-					return s.UserService.Unban(ctx, typedReq)
+					return s.UserService.Impersonate(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UnbanResponse)
+				typedResp, ok := resp.(*ImpersonateResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UnbanResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ImpersonateResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1326,7 +3558,7 @@ func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.Re
 	}
 
 	// Call service method
-	var respContent *UnbanResponse
+	var respContent *ImpersonateResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1337,7 +3569,7 @@ func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.Re
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UnbanResponse and nil error while calling Unban. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImpersonateResponse and nil error while calling Impersonate. nil responses are not supported"))
 		return
 	}
 
@@ -1361,7 +3593,7 @@ func (s *userServiceServer) serveUnbanProtobuf(ctx context.Context, resp http.Re
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveDelete(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequirePasswordChange(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1369,9 +3601,9 @@ func (s *userServiceServer) serveDelete(ctx context.Context, resp http.ResponseW
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveDeleteJSON(ctx, resp, req)
+		s.serveRequirePasswordChangeJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveDeleteProtobuf(ctx, resp, req)
+		s.serveRequirePasswordChangeProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1379,9 +3611,9 @@ func (s *userServiceServer) serveDelete(ctx context.Context, resp http.ResponseW
 	}
 }
 
-func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequirePasswordChangeJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Delete")
+	ctx = ctxsetters.WithMethodName(ctx, "RequirePasswordChange")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1394,29 +3626,29 @@ func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.Respo
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(DeleteRequest)
+	reqContent := new(RequirePasswordChangeRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.Delete
+	handler := s.UserService.RequirePasswordChange
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+		handler = func(ctx context.Context, req *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteRequest)
+					typedReq, ok := req.(*RequirePasswordChangeRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequirePasswordChangeRequest) when calling interceptor")
 					}
-					return s.UserService.Delete(ctx, typedReq)
+					return s.UserService.RequirePasswordChange(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteResponse)
+				typedResp, ok := resp.(*RequirePasswordChangeResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequirePasswordChangeResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1425,7 +3657,7 @@ func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.Respo
 	}
 
 	// Call service method
-	var respContent *DeleteResponse
+	var respContent *RequirePasswordChangeResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1436,7 +3668,7 @@ func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.Respo
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteResponse and nil error while calling Delete. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RequirePasswordChangeResponse and nil error while calling RequirePasswordChange. nil responses are not supported"))
 		return
 	}
 
@@ -1462,9 +3694,9 @@ func (s *userServiceServer) serveDeleteJSON(ctx context.Context, resp http.Respo
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequirePasswordChangeProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Delete")
+	ctx = ctxsetters.WithMethodName(ctx, "RequirePasswordChange")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1476,28 +3708,28 @@ func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.R
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(DeleteRequest)
+	reqContent := new(RequirePasswordChangeRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.Delete
+	handler := s.UserService.RequirePasswordChange
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+		handler = func(ctx context.Context, req *RequirePasswordChangeRequest) (*RequirePasswordChangeResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteRequest)
+					typedReq, ok := req.(*RequirePasswordChangeRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequirePasswordChangeRequest) when calling interceptor")
 					}
-					return s.UserService.Delete(ctx, typedReq)
+					return s.UserService.RequirePasswordChange(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteResponse)
+				typedResp, ok := resp.(*RequirePasswordChangeResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequirePasswordChangeResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1506,7 +3738,7 @@ func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.R
 	}
 
 	// Call service method
-	var respContent *DeleteResponse
+	var respContent *RequirePasswordChangeResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1517,7 +3749,7 @@ func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.R
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteResponse and nil error while calling Delete. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RequirePasswordChangeResponse and nil error while calling RequirePasswordChange. nil responses are not supported"))
 		return
 	}
 
@@ -1541,7 +3773,7 @@ func (s *userServiceServer) serveDeleteProtobuf(ctx context.Context, resp http.R
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUpdatePassword(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequestPasswordReset(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1549,9 +3781,9 @@ func (s *userServiceServer) serveUpdatePassword(ctx context.Context, resp http.R
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveUpdatePasswordJSON(ctx, resp, req)
+		s.serveRequestPasswordResetJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveUpdatePasswordProtobuf(ctx, resp, req)
+		s.serveRequestPasswordResetProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1559,9 +3791,9 @@ func (s *userServiceServer) serveUpdatePassword(ctx context.Context, resp http.R
 	}
 }
 
-func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequestPasswordResetJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
+	ctx = ctxsetters.WithMethodName(ctx, "RequestPasswordReset")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1574,29 +3806,29 @@ func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp ht
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(UpdatePasswordRequest)
+	reqContent := new(RequestPasswordResetRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.UpdatePassword
+	handler := s.UserService.RequestPasswordReset
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+		handler = func(ctx context.Context, req *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdatePasswordRequest)
+					typedReq, ok := req.(*RequestPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequestPasswordResetRequest) when calling interceptor")
 					}
-					return s.UserService.UpdatePassword(ctx, typedReq)
+					return s.UserService.RequestPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdatePasswordResponse)
+				typedResp, ok := resp.(*RequestPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequestPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1605,7 +3837,7 @@ func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp ht
 	}
 
 	// Call service method
-	var respContent *UpdatePasswordResponse
+	var respContent *RequestPasswordResetResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1616,7 +3848,7 @@ func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp ht
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePasswordResponse and nil error while calling UpdatePassword. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RequestPasswordResetResponse and nil error while calling RequestPasswordReset. nil responses are not supported"))
 		return
 	}
 
@@ -1642,9 +3874,9 @@ func (s *userServiceServer) serveUpdatePasswordJSON(ctx context.Context, resp ht
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveRequestPasswordResetProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "UpdatePassword")
+	ctx = ctxsetters.WithMethodName(ctx, "RequestPasswordReset")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1656,28 +3888,28 @@ func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, res
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(UpdatePasswordRequest)
+	reqContent := new(RequestPasswordResetRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.UpdatePassword
+	handler := s.UserService.RequestPasswordReset
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
+		handler = func(ctx context.Context, req *RequestPasswordResetRequest) (*RequestPasswordResetResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdatePasswordRequest)
+					typedReq, ok := req.(*RequestPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdatePasswordRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RequestPasswordResetRequest) when calling interceptor")
 					}
-					return s.UserService.UpdatePassword(ctx, typedReq)
+					return s.UserService.RequestPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdatePasswordResponse)
+				typedResp, ok := resp.(*RequestPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePasswordResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RequestPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1686,7 +3918,7 @@ func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, res
 	}
 
 	// Call service method
-	var respContent *UpdatePasswordResponse
+	var respContent *RequestPasswordResetResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1697,7 +3929,7 @@ func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, res
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePasswordResponse and nil error while calling UpdatePassword. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RequestPasswordResetResponse and nil error while calling RequestPasswordReset. nil responses are not supported"))
 		return
 	}
 
@@ -1721,7 +3953,7 @@ func (s *userServiceServer) serveUpdatePasswordProtobuf(ctx context.Context, res
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUpdateEmail(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveConfirmPasswordReset(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1729,9 +3961,9 @@ func (s *userServiceServer) serveUpdateEmail(ctx context.Context, resp http.Resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveUpdateEmailJSON(ctx, resp, req)
+		s.serveConfirmPasswordResetJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveUpdateEmailProtobuf(ctx, resp, req)
+		s.serveConfirmPasswordResetProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1739,9 +3971,9 @@ func (s *userServiceServer) serveUpdateEmail(ctx context.Context, resp http.Resp
 	}
 }
 
-func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveConfirmPasswordResetJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
+	ctx = ctxsetters.WithMethodName(ctx, "ConfirmPasswordReset")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1754,29 +3986,29 @@ func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(UpdateEmailRequest)
+	reqContent := new(ConfirmPasswordResetRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.UserService.UpdateEmail
+	handler := s.UserService.ConfirmPasswordReset
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+		handler = func(ctx context.Context, req *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdateEmailRequest)
+					typedReq, ok := req.(*ConfirmPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ConfirmPasswordResetRequest) when calling interceptor")
 					}
-					return s.UserService.UpdateEmail(ctx, typedReq)
+					return s.UserService.ConfirmPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdateEmailResponse)
+				typedResp, ok := resp.(*ConfirmPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ConfirmPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1785,7 +4017,7 @@ func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.
 	}
 
 	// Call service method
-	var respContent *UpdateEmailResponse
+	var respContent *ConfirmPasswordResetResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1796,7 +4028,7 @@ func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateEmailResponse and nil error while calling UpdateEmail. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ConfirmPasswordResetResponse and nil error while calling ConfirmPasswordReset. nil responses are not supported"))
 		return
 	}
 
@@ -1822,9 +4054,9 @@ func (s *userServiceServer) serveUpdateEmailJSON(ctx context.Context, resp http.
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *userServiceServer) serveUpdateEmailProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *userServiceServer) serveConfirmPasswordResetProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "UpdateEmail")
+	ctx = ctxsetters.WithMethodName(ctx, "ConfirmPasswordReset")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1836,28 +4068,28 @@ func (s *userServiceServer) serveUpdateEmailProtobuf(ctx context.Context, resp h
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(UpdateEmailRequest)
+	reqContent := new(ConfirmPasswordResetRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.UserService.UpdateEmail
+	handler := s.UserService.ConfirmPasswordReset
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *UpdateEmailRequest) (*UpdateEmailResponse, error) {
+		handler = func(ctx context.Context, req *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*UpdateEmailRequest)
+					typedReq, ok := req.(*ConfirmPasswordResetRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*UpdateEmailRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ConfirmPasswordResetRequest) when calling interceptor")
 					}
-					return s.UserService.UpdateEmail(ctx, typedReq)
+					return s.UserService.ConfirmPasswordReset(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*UpdateEmailResponse)
+				typedResp, ok := resp.(*ConfirmPasswordResetResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*UpdateEmailResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ConfirmPasswordResetResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1866,7 +4098,7 @@ func (s *userServiceServer) serveUpdateEmailProtobuf(ctx context.Context, resp h
 	}
 
 	// Call service method
-	var respContent *UpdateEmailResponse
+	var respContent *ConfirmPasswordResetResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1877,7 +4109,7 @@ func (s *userServiceServer) serveUpdateEmailProtobuf(ctx context.Context, resp h
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateEmailResponse and nil error while calling UpdateEmail. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ConfirmPasswordResetResponse and nil error while calling ConfirmPasswordReset. nil responses are not supported"))
 		return
 	}
 
@@ -2482,33 +4714,64 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 433 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x4d, 0xaf, 0x93, 0x50,
-	0x10, 0xcd, 0xeb, 0xfb, 0xb0, 0x6f, 0x78, 0xaf, 0xd6, 0xdb, 0x96, 0x20, 0x75, 0xa1, 0x6c, 0xac,
-	0x35, 0x29, 0xa6, 0x5d, 0xb9, 0x25, 0x75, 0x65, 0x62, 0x0c, 0xa6, 0x1b, 0x13, 0xd3, 0x50, 0x18,
-	0x4d, 0x23, 0xbd, 0x17, 0xb9, 0x20, 0xf1, 0x3f, 0xfb, 0x23, 0xcc, 0xfd, 0xb2, 0x40, 0x9a, 0xc0,
-	0x8e, 0x99, 0x73, 0xe6, 0xcc, 0xc9, 0x9d, 0x13, 0xc0, 0xce, 0xb3, 0xd8, 0x2f, 0x39, 0xe6, 0x3e,
-	0xc7, 0xfc, 0xf7, 0x31, 0xc6, 0x55, 0x96, 0xb3, 0x82, 0x91, 0x1b, 0xd1, 0xf3, 0xbe, 0xc1, 0xd3,
-	0x10, 0x7f, 0x1c, 0x79, 0x81, 0x79, 0x88, 0xbf, 0x4a, 0xe4, 0x05, 0x71, 0x61, 0x28, 0x20, 0x1a,
-	0x9d, 0xd0, 0xb9, 0x7a, 0x79, 0xb5, 0xb8, 0x0f, 0xff, 0xd7, 0x02, 0xcb, 0x22, 0xce, 0x2b, 0x96,
-	0x27, 0xce, 0x40, 0x61, 0xa6, 0x26, 0x04, 0x6e, 0xe4, 0xcc, 0xb5, 0xec, 0xcb, 0x6f, 0x6f, 0x0b,
-	0xe3, 0xb3, 0x3c, 0xcf, 0x18, 0xe5, 0x48, 0xa6, 0x70, 0x5b, 0xb0, 0x9f, 0x48, 0xb5, 0xb8, 0x2a,
-	0x1a, 0x5b, 0x07, 0xcd, 0xad, 0xde, 0x02, 0x20, 0x88, 0x68, 0x0f, 0x7f, 0xde, 0x6b, 0xb0, 0x24,
-	0x53, 0xaf, 0x72, 0xe0, 0x09, 0x2f, 0xe3, 0x18, 0x39, 0x97, 0xcc, 0x61, 0x68, 0x4a, 0x6f, 0x09,
-	0x0f, 0x3b, 0x7a, 0xe8, 0x27, 0xfa, 0x06, 0x1e, 0x35, 0xb7, 0x53, 0xf6, 0x2d, 0x3c, 0x6e, 0x31,
-	0xc5, 0x02, 0xfb, 0xe8, 0x2e, 0x61, 0x64, 0xc8, 0x9d, 0xc2, 0x7f, 0x60, 0xb6, 0xcb, 0x92, 0xa8,
-	0xc0, 0xcf, 0xfa, 0xb9, 0xfb, 0x5c, 0xeb, 0x15, 0x3c, 0xb0, 0x34, 0xd9, 0xb7, 0x2e, 0x66, 0xb1,
-	0x34, 0x31, 0x2a, 0x82, 0x42, 0xb1, 0x3a, 0x53, 0xd4, 0xf1, 0x2c, 0x8a, 0x95, 0xa1, 0x78, 0x6b,
-	0xb0, 0xdb, 0xab, 0x3b, 0xed, 0x7e, 0x02, 0xa2, 0x66, 0x3e, 0x9c, 0xa2, 0x63, 0x6a, 0xbc, 0xce,
-	0xe1, 0x5e, 0xf8, 0x41, 0xd1, 0x33, 0x66, 0x59, 0x9a, 0x48, 0x8e, 0x00, 0x85, 0x13, 0x05, 0xea,
-	0x04, 0x50, 0xac, 0x24, 0xe8, 0xf9, 0x30, 0x69, 0xe8, 0x75, 0x19, 0x58, 0xff, 0x1d, 0x80, 0xb5,
-	0xe3, 0x98, 0x7f, 0x51, 0x99, 0x27, 0xef, 0x61, 0x68, 0x82, 0x48, 0x66, 0x2b, 0xf1, 0x42, 0xab,
-	0x56, 0xee, 0x5d, 0xbb, 0xdd, 0xd6, 0x4b, 0x96, 0x70, 0x1d, 0x44, 0x94, 0x8c, 0x15, 0x7c, 0x0e,
-	0xa2, 0xfb, 0xac, 0xd6, 0xd1, 0xdc, 0x77, 0x70, 0x2b, 0xa3, 0x42, 0x88, 0xc2, 0xea, 0x19, 0x73,
-	0x27, 0x8d, 0x9e, 0x9e, 0xd8, 0xc0, 0x9d, 0x0a, 0x01, 0xd1, 0x70, 0x23, 0x3f, 0xee, 0xb4, 0xd9,
-	0xd4, 0x43, 0x1f, 0x61, 0xd4, 0x3c, 0x09, 0x99, 0x6b, 0xed, 0x4b, 0x19, 0x71, 0x5f, 0x5c, 0x06,
-	0xb5, 0x58, 0x00, 0x56, 0xed, 0x6d, 0x89, 0x53, 0x27, 0xd7, 0xcf, 0xe7, 0x3e, 0xbf, 0x80, 0x28,
-	0x8d, 0xc0, 0xfe, 0x3a, 0xf5, 0xe5, 0x6f, 0xe5, 0x50, 0x7e, 0x57, 0x1f, 0x7b, 0xc1, 0x3d, 0xdc,
-	0xc9, 0xef, 0xcd, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xdb, 0x6a, 0xce, 0x4b, 0x85, 0x04, 0x00,
-	0x00,
+	// 936 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0x4d, 0x6f, 0xdb, 0x46,
+	0x10, 0x85, 0x24, 0x7f, 0x48, 0x23, 0xc9, 0x4d, 0x56, 0xb2, 0x21, 0xaf, 0xe4, 0x46, 0x66, 0x0e,
+	0x75, 0x5c, 0xc0, 0x4e, 0x1c, 0xb4, 0x8d, 0x7b, 0x93, 0xd3, 0x00, 0x35, 0xfa, 0xcd, 0xc4, 0x3d,
+	0x04, 0x28, 0x58, 0x5a, 0x1c, 0xdb, 0x84, 0x29, 0x92, 0xe5, 0x52, 0x71, 0x7b, 0xe9, 0xb9, 0xf7,
+	0xfe, 0xb0, 0xfe, 0xa5, 0x62, 0xb9, 0x43, 0x8b, 0xa4, 0x57, 0x22, 0x91, 0x1b, 0x77, 0xe6, 0xcd,
+	0x9b, 0xd9, 0xd9, 0xdd, 0x37, 0x12, 0xec, 0x44, 0xe1, 0xf4, 0x78, 0x2e, 0x30, 0x3a, 0x16, 0x18,
+	0x7d, 0x70, 0xa7, 0x78, 0x14, 0x46, 0x41, 0x1c, 0xb0, 0x35, 0x69, 0x33, 0xfe, 0x86, 0x4f, 0x4c,
+	0xbc, 0x76, 0x45, 0x8c, 0x91, 0x89, 0x7f, 0xcc, 0x51, 0xc4, 0x8c, 0x43, 0x53, 0xba, 0x7c, 0x7b,
+	0x86, 0x83, 0xda, 0xb8, 0x76, 0xd0, 0x32, 0xef, 0xd7, 0xd2, 0x17, 0xda, 0x42, 0xdc, 0x05, 0x91,
+	0x33, 0xa8, 0x2b, 0x5f, 0xba, 0x66, 0x0c, 0xd6, 0x92, 0x98, 0x46, 0x62, 0x4f, 0xbe, 0xd9, 0x13,
+	0x68, 0x4f, 0x83, 0xe0, 0xd6, 0x45, 0x6b, 0x16, 0x38, 0x38, 0x58, 0x1b, 0xd7, 0x0e, 0x9a, 0x26,
+	0x28, 0xd3, 0x0f, 0x81, 0x83, 0xc6, 0x3f, 0x35, 0x78, 0xb4, 0x28, 0x40, 0x84, 0x81, 0x2f, 0x90,
+	0xf5, 0x61, 0x3d, 0x0e, 0x6e, 0xd1, 0xa7, 0xf4, 0x6a, 0x91, 0xab, 0xab, 0x5e, 0xa8, 0xeb, 0x29,
+	0x74, 0x23, 0xbc, 0x8a, 0x50, 0xdc, 0x58, 0x2a, 0x52, 0x15, 0xd1, 0x21, 0xe3, 0xbb, 0x84, 0x60,
+	0x0f, 0x00, 0xff, 0x0c, 0xdd, 0x08, 0x85, 0x65, 0xc7, 0x49, 0x2d, 0x0d, 0xb3, 0x45, 0x96, 0x49,
+	0x6c, 0x1c, 0x00, 0x9c, 0xd9, 0x7e, 0x85, 0x2e, 0x18, 0x9f, 0x41, 0x3b, 0x41, 0x52, 0xb9, 0x03,
+	0xd8, 0x14, 0xf3, 0xe9, 0x14, 0x85, 0x48, 0x90, 0x4d, 0x33, 0x5d, 0x1a, 0x87, 0xd0, 0xb9, 0xf0,
+	0x2f, 0xab, 0x91, 0x3e, 0x83, 0x2e, 0x61, 0x4b, 0x69, 0x3f, 0x87, 0xee, 0x37, 0xe8, 0x61, 0x8c,
+	0x55, 0x78, 0x0f, 0x61, 0x2b, 0x05, 0x97, 0x12, 0xff, 0x05, 0xdb, 0x17, 0xa1, 0x63, 0xc7, 0xf8,
+	0x33, 0x1d, 0x6a, 0x95, 0x3b, 0xb1, 0x0f, 0x9d, 0xc0, 0x73, 0xac, 0xc2, 0xbd, 0x68, 0x07, 0x9e,
+	0x93, 0xb2, 0x48, 0x88, 0x8f, 0x77, 0x0b, 0x88, 0x3a, 0x9d, 0xb6, 0x8f, 0x77, 0x29, 0xc4, 0x38,
+	0x81, 0x9d, 0x62, 0xea, 0xd2, 0x72, 0x7f, 0x04, 0xa6, 0x62, 0xde, 0xcc, 0x6c, 0xd7, 0x4b, 0x6b,
+	0x1d, 0x42, 0x4b, 0xd6, 0x83, 0xd2, 0x96, 0x16, 0x1b, 0x78, 0x4e, 0x82, 0x91, 0x4e, 0x59, 0x89,
+	0x72, 0xd2, 0x2d, 0xf2, 0xf1, 0x2e, 0x71, 0x1a, 0xc7, 0xd0, 0xcb, 0xf1, 0x95, 0x16, 0xf0, 0x25,
+	0x0c, 0x4c, 0xfc, 0x10, 0xdc, 0xe2, 0xc4, 0xf3, 0xde, 0xa2, 0x10, 0x6e, 0xe0, 0x8b, 0x2a, 0x67,
+	0xf2, 0x05, 0xec, 0x6a, 0xe2, 0x4a, 0xd3, 0xfd, 0x57, 0x83, 0x4d, 0x82, 0xb3, 0x2d, 0xa8, 0xbb,
+	0x0e, 0x11, 0xd7, 0x5d, 0x47, 0x5e, 0x6e, 0x49, 0x6f, 0xd9, 0xd7, 0xe8, 0xc7, 0xb4, 0xb3, 0x96,
+	0xb4, 0x4c, 0xa4, 0x41, 0xee, 0x7b, 0xea, 0xb9, 0xe8, 0xc7, 0x96, 0x1b, 0x52, 0xfb, 0x9b, 0xca,
+	0x70, 0x1e, 0xca, 0xd8, 0x69, 0x84, 0x76, 0x8c, 0x4e, 0xe6, 0x61, 0x90, 0x65, 0x12, 0xb3, 0x31,
+	0x74, 0x3c, 0x5b, 0xc4, 0x96, 0x40, 0xf4, 0x25, 0x60, 0x3d, 0x01, 0x80, 0xb4, 0xbd, 0x45, 0xf4,
+	0x27, 0x71, 0xe1, 0x65, 0x6d, 0x14, 0x5e, 0x96, 0xdc, 0xd1, 0x74, 0x1e, 0x45, 0xb2, 0xb0, 0x4d,
+	0xb5, 0x23, 0x5a, 0x1a, 0x2f, 0xa0, 0xf7, 0xbd, 0x2b, 0x62, 0xda, 0x54, 0xa5, 0xde, 0x4d, 0xa0,
+	0x9f, 0x0f, 0xa1, 0xb6, 0x3d, 0x83, 0xa6, 0x20, 0xdb, 0xa0, 0x36, 0x6e, 0x1c, 0xb4, 0x4f, 0xba,
+	0x47, 0x32, 0xe8, 0x88, 0x90, 0xe6, 0xbd, 0xdb, 0xf8, 0x05, 0xfa, 0xaa, 0xfd, 0xa9, 0x8b, 0xd2,
+	0xee, 0x01, 0x10, 0xc6, 0xba, 0xef, 0x6d, 0x8b, 0x2c, 0xe7, 0xce, 0x2a, 0x01, 0x32, 0x5e, 0xc0,
+	0x76, 0x81, 0xb2, 0xf4, 0x34, 0x47, 0xc0, 0x55, 0xc8, 0x4f, 0xf1, 0x0d, 0x46, 0x85, 0x16, 0x18,
+	0x5f, 0xc1, 0x50, 0xeb, 0x5d, 0xd0, 0x46, 0x89, 0x5b, 0xd5, 0xb9, 0x6e, 0xa6, 0x4b, 0xe3, 0x39,
+	0xb0, 0xf3, 0x59, 0x88, 0x91, 0x08, 0x7c, 0xbb, 0x9a, 0x42, 0x5c, 0x41, 0x2f, 0x17, 0xf1, 0xd1,
+	0x2a, 0x9c, 0xbf, 0x06, 0x8d, 0xa2, 0xc0, 0x7e, 0x0d, 0x23, 0x59, 0x8e, 0x1b, 0xdd, 0xbf, 0xf1,
+	0xd7, 0x37, 0xb6, 0x7f, 0x5d, 0xa9, 0xc6, 0x53, 0xd8, 0x5b, 0x12, 0x5b, 0xda, 0xe7, 0x53, 0x18,
+	0x52, 0x86, 0x8c, 0xb4, 0x60, 0x5c, 0x25, 0xeb, 0x2b, 0x18, 0xe9, 0x43, 0x4b, 0x93, 0xfe, 0x0a,
+	0xc3, 0xd7, 0x81, 0x7f, 0xe5, 0x46, 0x33, 0x6d, 0x52, 0x7d, 0x6f, 0x8b, 0x32, 0x59, 0x7f, 0x28,
+	0x93, 0xaf, 0x60, 0xa4, 0xe7, 0x2d, 0xab, 0xe8, 0xe4, 0xdf, 0x26, 0xb4, 0x2f, 0x84, 0xbc, 0x4a,
+	0xc9, 0xaf, 0x00, 0x76, 0x0a, 0xcd, 0x74, 0xf0, 0xb2, 0x6d, 0xf5, 0x52, 0x0a, 0xbf, 0x04, 0xf8,
+	0x4e, 0xd1, 0x4c, 0x49, 0x0e, 0xa1, 0x71, 0x66, 0xfb, 0xec, 0x91, 0x72, 0x2f, 0x86, 0x26, 0x7f,
+	0x9c, 0xb1, 0x10, 0xf6, 0x39, 0xac, 0x27, 0x63, 0x8d, 0x31, 0xe5, 0xcb, 0xce, 0x43, 0xde, 0xcb,
+	0xd9, 0x28, 0xe2, 0x25, 0x6c, 0xa8, 0x81, 0xc5, 0xc8, 0x9d, 0x9b, 0x75, 0xbc, 0x9f, 0x37, 0x52,
+	0xd0, 0x77, 0xb0, 0x95, 0x1f, 0x1f, 0x6c, 0x48, 0xdc, 0xba, 0x79, 0xc6, 0x47, 0x7a, 0x27, 0x91,
+	0x9d, 0x41, 0x3b, 0x33, 0x07, 0xd8, 0x20, 0x0b, 0xce, 0x8e, 0x1a, 0xbe, 0xab, 0xf1, 0x10, 0xc7,
+	0x3b, 0x78, 0xfc, 0x40, 0xe2, 0xd9, 0xa7, 0x69, 0x43, 0xf5, 0x33, 0x83, 0x3f, 0x59, 0xea, 0x27,
+	0xd6, 0x37, 0xd0, 0xc9, 0x8a, 0x1f, 0xa3, 0x02, 0x34, 0x1a, 0xca, 0xb9, 0xce, 0x45, 0x34, 0xdf,
+	0x42, 0x37, 0xa7, 0x56, 0x8c, 0x67, 0x13, 0xe7, 0x55, 0x91, 0x0f, 0xb5, 0x3e, 0x62, 0x7a, 0x0f,
+	0x3d, 0x8d, 0x4c, 0xb1, 0x71, 0x36, 0x46, 0xa7, 0x6f, 0x7c, 0x7f, 0x05, 0x62, 0x71, 0x0c, 0x19,
+	0x5d, 0x4a, 0x8f, 0xe1, 0xa1, 0xb8, 0xf1, 0x5d, 0x8d, 0x87, 0x38, 0x7e, 0x87, 0x6d, 0xad, 0x6e,
+	0x30, 0x23, 0xcd, 0xbf, 0x5c, 0x90, 0xf8, 0xd3, 0x95, 0x18, 0xca, 0xf0, 0x1b, 0xf4, 0x09, 0x9f,
+	0x7b, 0x91, 0x6c, 0x7f, 0x11, 0xbc, 0x44, 0x7a, 0xb8, 0xb1, 0x0a, 0xb2, 0xa0, 0xd7, 0x3d, 0xf8,
+	0x94, 0x7e, 0x85, 0xc8, 0x70, 0x63, 0x15, 0x44, 0xd1, 0x9f, 0xed, 0xbc, 0xef, 0x1f, 0x27, 0xff,
+	0x07, 0x2e, 0xe7, 0x57, 0xea, 0xc3, 0x92, 0x41, 0x97, 0x1b, 0xc9, 0xf7, 0xcb, 0xff, 0x07, 0x00,
+	0x8b, 0x25, 0xad, 0xe3, 0x3e, 0x0c, 0x00, 0x00,
 }